@@ -0,0 +1,32 @@
+// Command containerd-shim-sandboxrls-v1 is a containerd shim v2 binary that
+// drives sandbox-rls sandboxes through containerd's standard task API. It
+// is installed as containerd-shim-sandboxrls-v1 on $PATH and invoked by
+// containerd when a container's runtime is configured as
+// "io.containerd.sandboxrls.v1". Which sandbox-rls backend it drives is
+// selected via the SANDBOXRLS_RUNTIME environment variable (see
+// internal/shimservice); all backends must be blank-imported here so their
+// init() registration runs.
+package main
+
+import (
+	"os"
+
+	"github.com/containerd/containerd/runtime/v2/shim"
+
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/bwrap"
+	"github.com/ajaxzhan/sandbox-rls/internal/shimservice"
+
+	_ "github.com/ajaxzhan/sandbox-rls/internal/runtime/mock"
+	_ "github.com/ajaxzhan/sandbox-rls/internal/runtime/runsc"
+)
+
+func main() {
+	// bwrap re-execs this same binary as the in-sandbox supervisor; it
+	// must be handled before shim.Run (which expects containerd's own
+	// -namespace/-id/-address flags, not SupervisorArg) ever sees argv.
+	if len(os.Args) == 2 && os.Args[1] == bwrap.SupervisorArg {
+		os.Exit(bwrap.RunSupervisorMain())
+	}
+
+	shim.Run("io.containerd.sandboxrls.v1", shimservice.New)
+}
@@ -4,16 +4,23 @@
 package bwrap
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	rt "github.com/ajaxzhan/sandbox-rls/internal/runtime"
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/cgroup"
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/pty"
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/seccomp"
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/secprofile"
 	"github.com/ajaxzhan/sandbox-rls/pkg/types"
 )
 
@@ -30,6 +37,11 @@ type Config struct {
 
 	// EnableNetworking allows network access in sandboxes
 	EnableNetworking bool
+
+	// CgroupParent is the cgroup v2 directory under which each sandbox gets
+	// its own slice for resource limits and stats (default:
+	// "/sys/fs/cgroup/sandbox-rls.slice"). Ignored on non-Linux.
+	CgroupParent string
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -39,23 +51,154 @@ func DefaultConfig() *Config {
 		DefaultTimeout:   30 * time.Second,
 		WorkDir:          "/tmp/sandbox-rls",
 		EnableNetworking: false,
+		CgroupParent:     "/sys/fs/cgroup/sandbox-rls.slice",
+	}
+}
+
+// ExitError reports the exit code of a command run through a supervisor,
+// mirroring the information exec.ExitError carries for one-shot commands.
+type ExitError struct {
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.ExitCode)
+}
+
+// supervisorConn tracks the long-running supervisor process for a sandbox
+// and demultiplexes its framed stdout back to whichever Exec/ExecStream call
+// is waiting on a given exec ID.
+type supervisorConn struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	nextID  uint64
+
+	mu      sync.Mutex
+	pending map[string]chan *frame
+	closed  bool
+}
+
+// start begins a command on the supervisor and returns a channel of frames
+// belonging to that exec (stdout/stderr chunks followed by an exit frame).
+func (c *supervisorConn) start(req *types.ExecRequest) (string, <-chan *frame, error) {
+	id := fmt.Sprintf("%d", atomic.AddUint64(&c.nextID, 1))
+	ch := make(chan *frame, 16)
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return "", nil, fmt.Errorf("supervisor connection is closed")
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err := writeFrame(c.stdin, &frame{
+		ID:      id,
+		Kind:    frameExec,
+		Command: req.Command,
+		Env:     req.Env,
+		WorkDir: req.WorkDir,
+		Stdin:   []byte(req.Stdin),
+		TTY:     req.TTY,
+		Cols:    req.Cols,
+		Rows:    req.Rows,
+	})
+	c.writeMu.Unlock()
+
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return "", nil, fmt.Errorf("failed to dispatch exec to supervisor: %w", err)
+	}
+
+	return id, ch, nil
+}
+
+// writeStdin forwards a chunk of terminal input to id's in-flight,
+// TTY-backed exec.
+func (c *supervisorConn) writeStdin(id string, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return writeFrame(c.stdin, &frame{ID: id, Kind: frameStdin, Data: data})
+}
+
+// writeResize asks the supervisor to apply a new terminal size to id's
+// in-flight, TTY-backed exec.
+func (c *supervisorConn) writeResize(id string, cols, rows uint16) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return writeFrame(c.stdin, &frame{ID: id, Kind: frameResize, Cols: cols, Rows: rows})
+}
+
+// release stops routing frames for id, e.g. once the caller has consumed the
+// exit frame.
+func (c *supervisorConn) release(id string) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// demux reads frames off the supervisor's stdout and routes them to the
+// channel registered for their exec ID until stdout is closed.
+func (c *supervisorConn) demux(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		f, err := readFrame(reader)
+		if err != nil {
+			c.closeAll()
+			return
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[f.ID]
+		c.mu.Unlock()
+		if ok {
+			ch <- f
+		}
+	}
+}
+
+// closeAll marks the connection closed and unblocks any callers still
+// waiting on a pending exec (they'll see a closed channel).
+func (c *supervisorConn) closeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
 	}
 }
 
 // sandboxState holds internal state for a sandbox.
 type sandboxState struct {
-	sandbox *types.Sandbox
-	config  *rt.SandboxConfig
-	cmd     *exec.Cmd // The running process (if any)
-	cancel  context.CancelFunc
+	sandbox    *types.Sandbox
+	config     *rt.SandboxConfig
+	cmd        *exec.Cmd // The supervisor process for this sandbox (if running)
+	supervisor *supervisorConn
+	cgroupPath string
+	cancel     context.CancelFunc
+
+	// interactiveResize resizes the pty of this sandbox's in-flight
+	// ExecInteractive call, if any; set for the duration of that call so
+	// ResizeExec has something to reach. See ExecResizer.
+	interactiveResize func(cols, rows uint16) error
 }
 
 // BwrapRuntime implements runtime.RuntimeWithExecutor using bubblewrap.
 type BwrapRuntime struct {
-	mu       sync.RWMutex
-	config   *Config
-	states   map[string]*sandboxState
-	isLinux  bool
+	mu      sync.RWMutex
+	config  *Config
+	states  map[string]*sandboxState
+	isLinux bool
 }
 
 // New creates a new BwrapRuntime with the given configuration.
@@ -110,15 +253,45 @@ func (r *BwrapRuntime) Create(ctx context.Context, config *rt.SandboxConfig) (*t
 		MountPoint:  config.MountPoint,
 	}
 
-	r.states[config.ID] = &sandboxState{
+	state := &sandboxState{
 		sandbox: sandbox,
 		config:  config,
 	}
 
+	if r.isLinux {
+		cgroupPath, err := cgroup.Create(r.config.CgroupParent, config.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cgroup: %w", err)
+		}
+		if err := cgroup.Apply(cgroupPath, resourcesToLimits(config.Resources)); err != nil {
+			return nil, fmt.Errorf("failed to apply resource limits: %w", err)
+		}
+		state.cgroupPath = cgroupPath
+	}
+
+	r.states[config.ID] = state
+
 	return sandbox, nil
 }
 
-// Start starts a previously created sandbox.
+// resourcesToLimits translates a runtime.Resources request into cgroup v2
+// controller limits.
+func resourcesToLimits(res rt.Resources) cgroup.Limits {
+	return cgroup.Limits{
+		CPUWeight:        res.CPUShares,
+		CPUQuotaUs:       res.CPUQuotaUs,
+		CPUPeriodUs:      res.CPUPeriodUs,
+		MemoryLimitBytes: res.MemoryLimitBytes,
+		PidsMax:          res.PidsMax,
+		IOWeight:         res.IOWeight,
+	}
+}
+
+// Start launches the sandbox's long-lived supervisor process. The supervisor
+// owns the bwrap namespaces for the lifetime of the sandbox; Exec/ExecStream
+// dispatch commands into it instead of spawning a fresh bwrap invocation
+// each time, so namespace/mount setup happens once and in-sandbox state
+// (cwd, env, background processes) survives across execs.
 func (r *BwrapRuntime) Start(ctx context.Context, sandboxID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -132,9 +305,24 @@ func (r *BwrapRuntime) Start(ctx context.Context, sandboxID string) error {
 		return types.ErrAlreadyRunning
 	}
 
-	// On Linux, we would start a long-running bwrap process here.
-	// For now, we just mark it as running since exec will spawn processes as needed.
-	// In a full implementation, we might keep a shell process alive in the sandbox.
+	if r.isLinux {
+		cmd, conn, err := r.startSupervisor(state.config)
+		if err != nil {
+			return fmt.Errorf("failed to start supervisor: %w", err)
+		}
+		state.cmd = cmd
+		state.supervisor = conn
+
+		if state.cgroupPath != "" {
+			if err := cgroup.AddProcess(state.cgroupPath, cmd.Process.Pid); err != nil {
+				r.killSupervisor(state)
+				return fmt.Errorf("failed to add supervisor to cgroup: %w", err)
+			}
+		}
+	}
+	// In compatibility mode (non-Linux) there is no persistent process to
+	// hold namespaces open, so Exec/ExecStream fall back to spawning the
+	// command directly; marking the sandbox running is enough.
 
 	state.sandbox.Status = types.StatusRunning
 	now := time.Now()
@@ -143,6 +331,47 @@ func (r *BwrapRuntime) Start(ctx context.Context, sandboxID string) error {
 	return nil
 }
 
+// startSupervisor spawns the bwrap-wrapped supervisor process for config and
+// wires up its framed stdin/stdout.
+func (r *BwrapRuntime) startSupervisor(config *rt.SandboxConfig) (*exec.Cmd, *supervisorConn, error) {
+	cmd, err := r.buildSupervisorCommand(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	// Run in its own process group so Stop/Destroy can signal the whole
+	// sandbox (supervisor plus whatever it has spawned) in one shot.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start supervisor: %w", err)
+	}
+	// cmd.Start() has now duplicated ExtraFiles (the compiled seccomp
+	// program, if a security profile was applied) into the child; our copy
+	// of the fd just leaks otherwise, since exec.Cmd never closes it.
+	for _, f := range cmd.ExtraFiles {
+		f.Close()
+	}
+
+	conn := &supervisorConn{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[string]chan *frame),
+	}
+	go conn.demux(stdout)
+
+	return cmd, conn, nil
+}
+
 // Stop stops a running sandbox without destroying it.
 func (r *BwrapRuntime) Stop(ctx context.Context, sandboxID string) error {
 	r.mu.Lock()
@@ -162,11 +391,7 @@ func (r *BwrapRuntime) Stop(ctx context.Context, sandboxID string) error {
 		state.cancel()
 	}
 
-	// Kill the process if it's still running
-	if state.cmd != nil && state.cmd.Process != nil {
-		_ = state.cmd.Process.Kill()
-		state.cmd = nil
-	}
+	r.killSupervisor(state)
 
 	state.sandbox.Status = types.StatusStopped
 	now := time.Now()
@@ -175,6 +400,35 @@ func (r *BwrapRuntime) Stop(ctx context.Context, sandboxID string) error {
 	return nil
 }
 
+// killSupervisor signals the supervisor's whole process group, waits for our
+// direct child (the supervisor itself) to be reaped so it doesn't linger as
+// a zombie, and clears it from state. Safe to call when no supervisor was
+// started (compat mode).
+func (r *BwrapRuntime) killSupervisor(state *sandboxState) {
+	if state.cmd == nil || state.cmd.Process == nil {
+		return
+	}
+
+	if pgid, err := syscall.Getpgid(state.cmd.Process.Pid); err == nil {
+		_ = syscall.Kill(-pgid, syscall.SIGKILL)
+	} else {
+		_ = state.cmd.Process.Kill()
+	}
+
+	// SIGKILL can't be blocked, so this returns as soon as the kernel has
+	// finished tearing the process down; reaping it here (rather than
+	// leaving it to cmd's garbage collection) avoids a zombie on every
+	// Stop/Destroy and ensures it's no longer a cgroup member by the time
+	// the caller tries to remove the cgroup.
+	_ = state.cmd.Wait()
+
+	if state.supervisor != nil {
+		state.supervisor.closeAll()
+	}
+	state.cmd = nil
+	state.supervisor = nil
+}
+
 // Destroy destroys a sandbox, releasing all resources.
 func (r *BwrapRuntime) Destroy(ctx context.Context, sandboxID string) error {
 	r.mu.Lock()
@@ -189,8 +443,12 @@ func (r *BwrapRuntime) Destroy(ctx context.Context, sandboxID string) error {
 	if state.cancel != nil {
 		state.cancel()
 	}
-	if state.cmd != nil && state.cmd.Process != nil {
-		_ = state.cmd.Process.Kill()
+	r.killSupervisor(state)
+
+	if state.cgroupPath != "" {
+		if err := cgroup.Remove(state.cgroupPath); err != nil {
+			return err
+		}
 	}
 
 	delete(r.states, sandboxID)
@@ -212,6 +470,51 @@ func (r *BwrapRuntime) Get(ctx context.Context, sandboxID string) (*types.Sandbo
 	return &sandbox, nil
 }
 
+// Pid returns the OS process ID of sandboxID's supervisor process,
+// implementing runtime.PidProvider.
+func (r *BwrapRuntime) Pid(ctx context.Context, sandboxID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.states[sandboxID]
+	if !ok {
+		return 0, types.ErrSandboxNotFound
+	}
+	if state.cmd == nil || state.cmd.Process == nil {
+		return 0, types.ErrNotRunning
+	}
+	return state.cmd.Process.Pid, nil
+}
+
+// setInteractiveResize records resize as the way to reach the pty of
+// state's current interactive exec, for ResizeExec to call; pass nil when
+// that exec ends so a later ResizeExec call fails instead of resizing a
+// pty that's gone.
+func (r *BwrapRuntime) setInteractiveResize(state *sandboxState, resize func(cols, rows uint16) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state.interactiveResize = resize
+}
+
+// ResizeExec implements runtime.ExecResizer by resizing the pty of
+// sandboxID's in-flight ExecInteractive call, if any.
+func (r *BwrapRuntime) ResizeExec(ctx context.Context, sandboxID string, cols, rows uint16) error {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	var resize func(cols, rows uint16) error
+	if ok {
+		resize = state.interactiveResize
+	}
+	r.mu.RUnlock()
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+	if resize == nil {
+		return fmt.Errorf("bwrap: no interactive exec in progress for sandbox %s", sandboxID)
+	}
+	return resize(cols, rows)
+}
+
 // List returns all sandboxes managed by this runtime.
 func (r *BwrapRuntime) List(ctx context.Context) ([]*types.Sandbox, error) {
 	r.mu.RLock()
@@ -225,6 +528,68 @@ func (r *BwrapRuntime) List(ctx context.Context) ([]*types.Sandbox, error) {
 	return result, nil
 }
 
+// Stats returns a point-in-time snapshot of the sandbox's resource usage,
+// read from its cgroup v2 controller files.
+func (r *BwrapRuntime) Stats(ctx context.Context, sandboxID string) (*types.SandboxStats, error) {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	if !ok {
+		r.mu.RUnlock()
+		return nil, types.ErrSandboxNotFound
+	}
+	cgroupPath := state.cgroupPath
+	r.mu.RUnlock()
+
+	if cgroupPath == "" {
+		return nil, fmt.Errorf("stats are not available: no cgroup for this sandbox")
+	}
+
+	stats, err := cgroup.ReadStats(cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SandboxStats{
+		CPUUsageUsec:       stats.CPUUsageUsec,
+		MemoryCurrentBytes: stats.MemoryCurrentBytes,
+		MemoryPeakBytes:    stats.MemoryPeakBytes,
+		PIDs:               stats.PIDs,
+		Timestamp:          time.Now(),
+	}, nil
+}
+
+// statsPollInterval is how often StatsStream polls the cgroup for a new
+// snapshot.
+const statsPollInterval = time.Second
+
+// StatsStream sends a stats snapshot on output once per statsPollInterval
+// until ctx is done, then closes output.
+func (r *BwrapRuntime) StatsStream(ctx context.Context, sandboxID string, output chan<- *types.SandboxStats) error {
+	defer close(output)
+
+	ticker := time.NewTicker(statsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := r.Stats(ctx, sandboxID)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case output <- stats:
+		case <-ctx.Done():
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // Exec executes a command in the sandbox and returns the result.
 func (r *BwrapRuntime) Exec(ctx context.Context, sandboxID string, req *types.ExecRequest) (*types.ExecResult, error) {
 	r.mu.RLock()
@@ -240,6 +605,7 @@ func (r *BwrapRuntime) Exec(ctx context.Context, sandboxID string, req *types.Ex
 	}
 
 	config := state.config
+	supervisor := state.supervisor
 	r.mu.RUnlock()
 
 	// Set timeout if specified
@@ -253,36 +619,27 @@ func (r *BwrapRuntime) Exec(ctx context.Context, sandboxID string, req *types.Ex
 
 	start := time.Now()
 
-	var cmd *exec.Cmd
-	if r.isLinux {
-		cmd = r.buildBwrapCommand(ctx, config, req)
-	} else {
-		// Compatibility mode: run command directly (no isolation)
-		cmd = r.buildLocalCommand(ctx, config, req)
+	if supervisor != nil {
+		return r.execViaSupervisor(ctx, supervisor, req, start)
 	}
 
-	// Capture output
+	// Compatibility mode: no supervisor, spawn the command directly.
+	cmd := r.buildLocalCommand(ctx, config, req)
 	stdout, err := cmd.Output()
 	duration := time.Since(start)
 
-	result := &types.ExecResult{
-		Duration: duration,
-	}
+	result := &types.ExecResult{Duration: duration}
 
 	if err != nil {
-		// Check for timeout first - context deadline exceeded takes priority
 		if ctx.Err() == context.DeadlineExceeded {
 			return nil, types.ErrTimeout
 		}
-
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
 			result.Stderr = string(exitErr.Stderr)
 			result.Stdout = string(stdout)
-			// Non-zero exit is not an error from our perspective
 			return result, nil
 		}
-
 		return nil, fmt.Errorf("exec failed: %w", err)
 	}
 
@@ -291,6 +648,44 @@ func (r *BwrapRuntime) Exec(ctx context.Context, sandboxID string, req *types.Ex
 	return result, nil
 }
 
+// execViaSupervisor dispatches req to an already-running supervisor and
+// accumulates its output until the exit frame arrives.
+func (r *BwrapRuntime) execViaSupervisor(ctx context.Context, supervisor *supervisorConn, req *types.ExecRequest, start time.Time) (*types.ExecResult, error) {
+	id, frames, err := supervisor.start(req)
+	if err != nil {
+		return nil, err
+	}
+	defer supervisor.release(id)
+
+	result := &types.ExecResult{}
+	var stdout, stderr []byte
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, types.ErrTimeout
+		case f, ok := <-frames:
+			if !ok {
+				return nil, fmt.Errorf("supervisor connection closed before exec completed")
+			}
+			switch f.Kind {
+			case frameStdout:
+				stdout = append(stdout, f.Data...)
+			case frameStderr:
+				stderr = append(stderr, f.Data...)
+			case frameExit:
+				result.Stdout = string(stdout)
+				result.Stderr = string(stderr)
+				result.ExitCode = f.ExitCode
+				result.Duration = time.Since(start)
+				return result, nil
+			case frameError:
+				return nil, fmt.Errorf("supervisor failed to run command: %s", f.Message)
+			}
+		}
+	}
+}
+
 // ExecStream executes a command and streams output.
 func (r *BwrapRuntime) ExecStream(ctx context.Context, sandboxID string, req *types.ExecRequest, output chan<- []byte) error {
 	r.mu.RLock()
@@ -308,6 +703,7 @@ func (r *BwrapRuntime) ExecStream(ctx context.Context, sandboxID string, req *ty
 	}
 
 	config := state.config
+	supervisor := state.supervisor
 	r.mu.RUnlock()
 
 	// Set timeout if specified
@@ -319,14 +715,12 @@ func (r *BwrapRuntime) ExecStream(ctx context.Context, sandboxID string, req *ty
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	var cmd *exec.Cmd
-	if r.isLinux {
-		cmd = r.buildBwrapCommand(ctx, config, req)
-	} else {
-		cmd = r.buildLocalCommand(ctx, config, req)
+	if supervisor != nil {
+		return r.execStreamViaSupervisor(ctx, supervisor, req, output)
 	}
 
-	// Get stdout pipe
+	cmd := r.buildLocalCommand(ctx, config, req)
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		close(output)
@@ -338,7 +732,6 @@ func (r *BwrapRuntime) ExecStream(ctx context.Context, sandboxID string, req *ty
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Stream output
 	go func() {
 		defer close(output)
 		buf := make([]byte, 4096)
@@ -362,14 +755,222 @@ func (r *BwrapRuntime) ExecStream(ctx context.Context, sandboxID string, req *ty
 	return cmd.Wait()
 }
 
-// buildBwrapCommand builds a bwrap command for Linux.
-func (r *BwrapRuntime) buildBwrapCommand(ctx context.Context, config *rt.SandboxConfig, req *types.ExecRequest) *exec.Cmd {
+// execStreamViaSupervisor dispatches req to an already-running supervisor,
+// forwarding stdout chunks to output as they arrive until the exit frame.
+func (r *BwrapRuntime) execStreamViaSupervisor(ctx context.Context, supervisor *supervisorConn, req *types.ExecRequest, output chan<- []byte) error {
+	defer close(output)
+
+	id, frames, err := supervisor.start(req)
+	if err != nil {
+		return err
+	}
+	defer supervisor.release(id)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return types.ErrTimeout
+		case f, ok := <-frames:
+			if !ok {
+				return fmt.Errorf("supervisor connection closed before exec completed")
+			}
+			switch f.Kind {
+			case frameStdout:
+				select {
+				case output <- f.Data:
+				case <-ctx.Done():
+					return types.ErrTimeout
+				}
+			case frameExit:
+				if f.ExitCode != 0 {
+					return &ExitError{ExitCode: f.ExitCode}
+				}
+				return nil
+			case frameError:
+				return fmt.Errorf("supervisor failed to run command: %s", f.Message)
+			}
+		}
+	}
+}
+
+// ExecInteractive runs req (which should set TTY) inside the sandbox with
+// a pty attached, copying bytes between it and tty until the command exits
+// or ctx is done.
+func (r *BwrapRuntime) ExecInteractive(ctx context.Context, sandboxID string, req *types.ExecRequest, tty rt.TTYStream) error {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	if !ok {
+		r.mu.RUnlock()
+		return types.ErrSandboxNotFound
+	}
+
+	if state.sandbox.Status != types.StatusRunning {
+		r.mu.RUnlock()
+		return types.ErrNotRunning
+	}
+
+	config := state.config
+	supervisor := state.supervisor
+	r.mu.RUnlock()
+
+	// Unlike Exec/ExecStream, an interactive session has no natural
+	// duration, so only bound it if the caller asked for that explicitly.
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	if supervisor != nil {
+		return r.execInteractiveViaSupervisor(ctx, state, supervisor, req, tty)
+	}
+	return r.execInteractiveLocal(ctx, state, config, req, tty)
+}
+
+// execInteractiveViaSupervisor dispatches a TTY-backed req to an
+// already-running supervisor, pumping tty's input to it as frameStdin
+// frames and its pty output back via tty.Write, until the exit frame.
+func (r *BwrapRuntime) execInteractiveViaSupervisor(ctx context.Context, state *sandboxState, supervisor *supervisorConn, req *types.ExecRequest, tty rt.TTYStream) error {
+	id, frames, err := supervisor.start(req)
+	if err != nil {
+		return err
+	}
+	defer supervisor.release(id)
+
+	r.setInteractiveResize(state, func(cols, rows uint16) error {
+		return supervisor.writeResize(id, cols, rows)
+	})
+	defer r.setInteractiveResize(state, nil)
+
+	stopResize := pty.ForwardHostResize(func(cols, rows uint16) {
+		_ = supervisor.writeResize(id, cols, rows)
+	}, tty)
+	defer stopResize()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := tty.Read(buf)
+			if n > 0 {
+				if werr := supervisor.writeStdin(id, buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return types.ErrTimeout
+		case f, ok := <-frames:
+			if !ok {
+				return fmt.Errorf("supervisor connection closed before exec completed")
+			}
+			switch f.Kind {
+			case frameStdout:
+				if _, err := tty.Write(f.Data); err != nil {
+					return err
+				}
+			case frameExit:
+				if f.ExitCode != 0 {
+					return &ExitError{ExitCode: f.ExitCode}
+				}
+				return nil
+			case frameError:
+				return fmt.Errorf("supervisor failed to run command: %s", f.Message)
+			}
+		}
+	}
+}
+
+// execInteractiveLocal runs req with a pty attached directly (compatibility
+// mode, no supervisor), used on non-Linux hosts.
+func (r *BwrapRuntime) execInteractiveLocal(ctx context.Context, state *sandboxState, config *rt.SandboxConfig, req *types.ExecRequest, tty rt.TTYStream) error {
+	pair, err := pty.Open()
+	if err != nil {
+		return fmt.Errorf("failed to allocate pty: %w", err)
+	}
+	defer pair.Close()
+
+	r.setInteractiveResize(state, func(cols, rows uint16) error {
+		return pair.Resize(cols, rows)
+	})
+	defer r.setInteractiveResize(state, nil)
+
+	cols, rows := req.Cols, req.Rows
+	if cols == 0 {
+		cols = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	if err := pair.Resize(cols, rows); err != nil {
+		return fmt.Errorf("failed to set initial pty size: %w", err)
+	}
+
+	cmd := r.buildLocalCommand(ctx, config, req)
+	cmd.Stdin = pair.Slave
+	cmd.Stdout = pair.Slave
+	cmd.Stderr = pair.Slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	pair.Slave.Close()
+
+	stopResize := pty.ForwardHostResize(func(cols, rows uint16) {
+		_ = pair.Resize(cols, rows)
+	}, tty)
+	defer stopResize()
+
+	go io.Copy(pair.Master, tty)
+
+	copyDone := make(chan struct{})
+	go func() {
+		defer close(copyDone)
+		_, _ = io.Copy(tty, pair.Master)
+	}()
+
+	waitDone := make(chan error, 1)
+	go func() { waitDone <- cmd.Wait() }()
+
+	select {
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		<-waitDone
+		pair.Master.Close()
+		<-copyDone
+		return types.ErrTimeout
+	case err := <-waitDone:
+		pair.Master.Close()
+		<-copyDone
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return &ExitError{ExitCode: exitErr.ExitCode()}
+		}
+		return err
+	}
+}
+
+// buildSupervisorCommand builds the bwrap invocation that launches this
+// binary, re-exec'd with SupervisorArg, as the long-lived sandbox process.
+func (r *BwrapRuntime) buildSupervisorCommand(config *rt.SandboxConfig) (*exec.Cmd, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve own executable path: %w", err)
+	}
+
 	args := []string{
 		"--ro-bind", "/usr", "/usr",
 		"--ro-bind", "/lib", "/lib",
 		"--ro-bind", "/lib64", "/lib64",
 		"--ro-bind", "/bin", "/bin",
 		"--ro-bind", "/sbin", "/sbin",
+		"--ro-bind", self, self,
 		"--proc", "/proc",
 		"--dev", "/dev",
 		"--tmpfs", "/tmp",
@@ -379,12 +980,10 @@ func (r *BwrapRuntime) buildBwrapCommand(ctx context.Context, config *rt.Sandbox
 		"--die-with-parent",
 	}
 
-	// Network isolation
 	if !r.config.EnableNetworking {
 		args = append(args, "--unshare-net")
 	}
 
-	// Bind the codebase
 	if config.CodebasePath != "" {
 		workdir := "/workspace"
 		if config.MountPoint != "" {
@@ -394,23 +993,95 @@ func (r *BwrapRuntime) buildBwrapCommand(ctx context.Context, config *rt.Sandbox
 		args = append(args, "--chdir", workdir)
 	}
 
-	// Add the shell command
-	args = append(args, "/bin/sh", "-c", req.Command)
+	cmd := exec.Command(r.config.BwrapPath)
+	cmd.Env = os.Environ()
+	cmd.Stderr = os.Stderr
 
-	cmd := exec.CommandContext(ctx, r.config.BwrapPath, args...)
+	// --cap-add/--cap-drop/--seccomp are bwrap's own flags, so they must be
+	// appended before the trailing "self SupervisorArg", which is the
+	// command bwrap execs once its own setup is done.
+	if err := r.applySecurityProfile(cmd, &args, config.SecurityProfile); err != nil {
+		return nil, err
+	}
 
-	// Set environment variables
-	cmd.Env = os.Environ()
-	for k, v := range req.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	args = append(args, self, SupervisorArg)
+	cmd.Args = append([]string{cmd.Path}, args...)
+
+	return cmd, nil
+}
+
+// applySecurityProfile resolves config's SecurityProfile (by name if one
+// was given), appends the resulting --cap-add/--cap-drop flags to args, and
+// compiles its seccomp filter into a temp file that is inherited by cmd and
+// referenced with --seccomp <fd>.
+func (r *BwrapRuntime) applySecurityProfile(cmd *exec.Cmd, args *[]string, profile types.SecurityProfile) error {
+	if profile.Name != "" {
+		resolved, ok := secprofile.Get(profile.Name)
+		if !ok {
+			return fmt.Errorf("unknown security profile %q", profile.Name)
+		}
+		profile = resolved
 	}
 
-	// Set up stdin if provided
-	if req.Stdin != "" {
-		cmd.Stdin = nil // We'll handle this differently if needed
+	for _, capName := range profile.CapDrop {
+		*args = append(*args, "--cap-drop", capName)
+	}
+	for _, capName := range profile.CapAdd {
+		*args = append(*args, "--cap-add", capName)
 	}
 
-	return cmd
+	if profile.Seccomp == nil {
+		return nil
+	}
+
+	seccompFile, err := r.compileSeccompProfile(profile.Seccomp)
+	if err != nil {
+		return fmt.Errorf("failed to compile seccomp profile: %w", err)
+	}
+
+	// bwrap reads the fd number for --seccomp from its own argv, so the
+	// flag must reference where this file lands once inherited: stdin(0),
+	// stdout(1), stderr(2), then ExtraFiles in order starting at 3.
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, seccompFile)
+	*args = append(*args, "--seccomp", fmt.Sprintf("%d", fd))
+
+	return nil
+}
+
+// compileSeccompProfile resolves (loading from profile.Path if set) and
+// compiles profile into a BPF program, writes it to a temp file, and
+// returns that file open for reading so the caller can hand it to
+// cmd.ExtraFiles.
+func (r *BwrapRuntime) compileSeccompProfile(profile *types.SeccompProfile) (*os.File, error) {
+	resolved, err := seccomp.Resolve(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := seccomp.Compile(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "sandbox-rls-seccomp-*.bpf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	// The file is only needed for the lifetime of cmd.Start(); unlinking it
+	// now means the kernel cleans it up once the last fd to it closes.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(program); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write compiled seccomp program: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to rewind seccomp program file: %w", err)
+	}
+
+	return tmp, nil
 }
 
 // buildLocalCommand builds a local command for non-Linux systems (development mode).
@@ -449,3 +1120,14 @@ func IsBwrapAvailable() bool {
 
 // Verify interface compliance at compile time
 var _ rt.RuntimeWithExecutor = (*BwrapRuntime)(nil)
+var _ rt.PidProvider = (*BwrapRuntime)(nil)
+
+func init() {
+	rt.Register("bwrap", func(config interface{}) (rt.RuntimeWithExecutor, error) {
+		cfg, ok := config.(*Config)
+		if config != nil && !ok {
+			return nil, fmt.Errorf("bwrap: expected *bwrap.Config, got %T", config)
+		}
+		return New(cfg), nil
+	})
+}
@@ -0,0 +1,100 @@
+package bwrap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// frameKind identifies the kind of message exchanged with a supervisor.
+type frameKind string
+
+const (
+	// frameExec is sent from the runtime to the supervisor to start a command.
+	frameExec frameKind = "exec"
+	// frameStdout/frameStderr carry a chunk of output for an in-flight exec.
+	frameStdout frameKind = "stdout"
+	frameStderr frameKind = "stderr"
+	// frameExit reports the exit code of a finished exec.
+	frameExit frameKind = "exit"
+	// frameError reports that the supervisor could not start the command at all.
+	frameError frameKind = "error"
+	// frameStdin carries a chunk of terminal input for an in-flight
+	// TTY-backed exec, sent from the runtime to the supervisor.
+	frameStdin frameKind = "stdin"
+	// frameResize asks the supervisor to apply a new terminal size to an
+	// in-flight TTY-backed exec's pty.
+	frameResize frameKind = "resize"
+)
+
+// frame is one message on the supervisor's command channel. Frames are
+// wire-encoded as JSON, each prefixed with a 4-byte big-endian length so
+// readFrame never has to guess where one message ends and the next begins.
+type frame struct {
+	ID       string            `json:"id"`
+	Kind     frameKind         `json:"kind"`
+	Command  string            `json:"command,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	WorkDir  string            `json:"work_dir,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	ExitCode int               `json:"exit_code,omitempty"`
+	Message  string            `json:"message,omitempty"`
+
+	// Stdin is set on a frameExec to supply a non-interactive exec's
+	// entire input up front, since such execs aren't long-lived enough
+	// to warrant the frameStdin streaming a TTY-backed exec uses.
+	Stdin []byte `json:"stdin,omitempty"`
+
+	// TTY, Cols and Rows are set on a frameExec to request a pty-backed
+	// exec with the given initial size. Cols/Rows are also reused on a
+	// frameResize to carry the new size.
+	TTY  bool   `json:"tty,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+}
+
+const maxFrameSize = 16 << 20 // 16MiB, generous enough for a single output chunk
+
+// writeFrame encodes f and writes it to w, prefixed with its length.
+func writeFrame(w io.Writer, f *frame) error {
+	payload, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("marshal frame: %w", err)
+	}
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return fmt.Errorf("write frame header: %w", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("write frame payload: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads one length-prefixed frame from r.
+func readFrame(r *bufio.Reader) (*frame, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds max size %d", size, maxFrameSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("read frame payload: %w", err)
+	}
+
+	var f frame
+	if err := json.Unmarshal(payload, &f); err != nil {
+		return nil, fmt.Errorf("unmarshal frame: %w", err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,320 @@
+package bwrap
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/pty"
+)
+
+// SupervisorArg is the argument BwrapRuntime re-execs itself with to become
+// the in-sandbox supervisor. A host binary that embeds this package must
+// check for it at the top of main, e.g.:
+//
+//	if len(os.Args) == 2 && os.Args[1] == bwrap.SupervisorArg {
+//	        os.Exit(bwrap.RunSupervisorMain())
+//	}
+const SupervisorArg = "__bwrap_supervisor__"
+
+// RunSupervisorMain runs the supervisor loop against the process's own
+// stdin/stdout and returns the process exit code. It is the counterpart to
+// SupervisorArg and is meant to be called from main() before any other
+// startup work, since it never returns until the parent closes the pipe.
+func RunSupervisorMain() int {
+	if err := RunSupervisor(os.Stdin, os.Stdout); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// RunSupervisor reads exec request frames from stdin and, for each one,
+// spawns the requested command and streams its stdout/stderr/exit status
+// back on stdout as framed messages. It runs until stdin is closed, which
+// happens when the parent (the BwrapRuntime that spawned this process) goes
+// away — bwrap's --die-with-parent then tears down the whole sandbox.
+//
+// Multiple execs can be in flight at once: each runs in its own goroutine,
+// and a mutex serializes writes to stdout so frames from concurrent execs
+// never interleave mid-message.
+func RunSupervisor(stdin io.Reader, stdout io.Writer) error {
+	reader := bufio.NewReader(stdin)
+	var writeMu sync.Mutex
+	var wg sync.WaitGroup
+
+	var activeMu sync.Mutex
+	active := make(map[string]*activeExec)
+
+	for {
+		f, err := readFrame(reader)
+		if err != nil {
+			break
+		}
+
+		switch f.Kind {
+		case frameExec:
+			if f.TTY {
+				// Register the exec before it's actually started so a
+				// frameStdin/frameResize that arrives while the pty is
+				// still being opened is buffered by activeExec instead of
+				// being dropped by a lookup racing runExecFrameTTY's setup.
+				ae := &activeExec{}
+				activeMu.Lock()
+				active[f.ID] = ae
+				activeMu.Unlock()
+
+				wg.Add(1)
+				go func(req *frame, ae *activeExec) {
+					defer wg.Done()
+					runExecFrameTTY(req, stdout, &writeMu, &activeMu, active, ae)
+				}(f, ae)
+			} else {
+				wg.Add(1)
+				go func(req *frame) {
+					defer wg.Done()
+					runExecFrame(req, stdout, &writeMu)
+				}(f)
+			}
+		case frameStdin:
+			activeMu.Lock()
+			ae := active[f.ID]
+			activeMu.Unlock()
+			if ae != nil {
+				ae.writeStdin(f.Data)
+			}
+		case frameResize:
+			activeMu.Lock()
+			ae := active[f.ID]
+			activeMu.Unlock()
+			if ae != nil {
+				ae.resize(f.Cols, f.Rows)
+			}
+		}
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// activeExec tracks a running TTY-backed exec so the main read loop can
+// route later frameStdin/frameResize frames for its ID to the right pty.
+// It is registered before the pty is actually opened, so writeStdin/resize
+// buffer their calls until ready makes the real pty available.
+type activeExec struct {
+	mu sync.Mutex
+
+	pair *pty.Pair
+
+	pendingInput  [][]byte
+	pendingResize *frame
+}
+
+// ready makes pair available to writeStdin/resize, flushing anything they
+// buffered while the pty was still being set up.
+func (ae *activeExec) ready(pair *pty.Pair) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	ae.pair = pair
+	for _, data := range ae.pendingInput {
+		_, _ = pair.Master.Write(data)
+	}
+	ae.pendingInput = nil
+
+	if ae.pendingResize != nil {
+		_ = pair.Resize(ae.pendingResize.Cols, ae.pendingResize.Rows)
+		ae.pendingResize = nil
+	}
+}
+
+// writeStdin forwards data to the pty if it's ready, or buffers it for
+// ready to flush otherwise.
+func (ae *activeExec) writeStdin(data []byte) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	if ae.pair != nil {
+		_, _ = ae.pair.Master.Write(data)
+		return
+	}
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	ae.pendingInput = append(ae.pendingInput, buf)
+}
+
+// resize applies a new size if the pty is ready, or remembers it for ready
+// to apply otherwise.
+func (ae *activeExec) resize(cols, rows uint16) {
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	if ae.pair != nil {
+		_ = ae.pair.Resize(cols, rows)
+		return
+	}
+	ae.pendingResize = &frame{Cols: cols, Rows: rows}
+}
+
+// runExecFrameTTY runs req's command with a pty attached instead of plain
+// pipes. ae is already registered in active under req.ID by the caller;
+// runExecFrameTTY makes it ready once the pty exists and removes it from
+// active once the exec finishes.
+func runExecFrameTTY(req *frame, stdout io.Writer, writeMu *sync.Mutex, activeMu *sync.Mutex, active map[string]*activeExec, ae *activeExec) {
+	send := func(f *frame) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = writeFrame(stdout, f)
+	}
+	cleanup := func() {
+		activeMu.Lock()
+		delete(active, req.ID)
+		activeMu.Unlock()
+	}
+
+	pair, err := pty.Open()
+	if err != nil {
+		send(&frame{ID: req.ID, Kind: frameError, Message: err.Error()})
+		cleanup()
+		return
+	}
+
+	cols, rows := req.Cols, req.Rows
+	if cols == 0 {
+		cols = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	_ = pair.Resize(cols, rows)
+
+	cmd := exec.Command("/bin/sh", "-c", req.Command)
+	if req.WorkDir != "" {
+		cmd.Dir = req.WorkDir
+	}
+	cmd.Env = os.Environ()
+	for k, v := range req.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdin = pair.Slave
+	cmd.Stdout = pair.Slave
+	cmd.Stderr = pair.Slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+	if err := cmd.Start(); err != nil {
+		send(&frame{ID: req.ID, Kind: frameError, Message: err.Error()})
+		pair.Close()
+		cleanup()
+		return
+	}
+	// The child now owns the slave end; our copy just keeps master's Read
+	// blocked on the child rather than on a second open reference.
+	pair.Slave.Close()
+
+	ae.ready(pair)
+	defer cleanup()
+
+	copyDone := make(chan struct{})
+	go func() {
+		defer close(copyDone)
+		buf := make([]byte, 4096)
+		for {
+			n, err := pair.Master.Read(buf)
+			if n > 0 {
+				data := make([]byte, n)
+				copy(data, buf[:n])
+				send(&frame{ID: req.ID, Kind: frameStdout, Data: data})
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	exitCode := 0
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else {
+			exitCode = -1
+		}
+	}
+	pair.Master.Close()
+	<-copyDone
+	send(&frame{ID: req.ID, Kind: frameExit, ExitCode: exitCode})
+}
+
+// runExecFrame executes req's command and writes its output and exit status
+// back to stdout, guarded by writeMu.
+func runExecFrame(req *frame, stdout io.Writer, writeMu *sync.Mutex) {
+	send := func(f *frame) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = writeFrame(stdout, f)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", req.Command)
+	if req.WorkDir != "" {
+		cmd.Dir = req.WorkDir
+	}
+	cmd.Env = os.Environ()
+	for k, v := range req.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if len(req.Stdin) > 0 {
+		cmd.Stdin = bytes.NewReader(req.Stdin)
+	}
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		send(&frame{ID: req.ID, Kind: frameError, Message: err.Error()})
+		return
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		send(&frame{ID: req.ID, Kind: frameError, Message: err.Error()})
+		return
+	}
+
+	if err := cmd.Start(); err != nil {
+		send(&frame{ID: req.ID, Kind: frameError, Message: err.Error()})
+		return
+	}
+
+	var pipeWg sync.WaitGroup
+	pipeWg.Add(2)
+	go streamPipe(stdoutPipe, req.ID, frameStdout, send, &pipeWg)
+	go streamPipe(stderrPipe, req.ID, frameStderr, send, &pipeWg)
+	pipeWg.Wait()
+
+	exitCode := 0
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else {
+			exitCode = -1
+		}
+	}
+	send(&frame{ID: req.ID, Kind: frameExit, ExitCode: exitCode})
+}
+
+// streamPipe reads r until EOF, emitting each chunk as a frame of kind.
+func streamPipe(r io.Reader, id string, kind frameKind, send func(*frame), wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			send(&frame{ID: id, Kind: kind, Data: data})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
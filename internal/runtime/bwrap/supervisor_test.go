@@ -0,0 +1,184 @@
+package bwrap
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+)
+
+// readFrameUntil reads frames from r, skipping any that aren't of kind k,
+// and returns the first match. It fails the test on a read error, a
+// frameError frame, or if k never shows up within a generous frame budget.
+func readFrameUntil(t *testing.T, r *bufio.Reader, k frameKind) *frame {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		f, err := readFrame(r)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		if f.Kind == frameError {
+			t.Fatalf("supervisor reported an error: %s", f.Message)
+		}
+		if f.Kind == k {
+			return f
+		}
+	}
+	t.Fatalf("never saw a %q frame", k)
+	return nil
+}
+
+// newSupervisorHarness starts RunSupervisor against a pair of pipes and
+// returns the ends a test drives as the runtime side of the connection,
+// plus a cleanup that closes the connection and waits for RunSupervisor to
+// return.
+func newSupervisorHarness(t *testing.T) (send func(*frame), recv *bufio.Reader, stop func()) {
+	t.Helper()
+
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() { done <- RunSupervisor(stdinR, stdoutW) }()
+
+	send = func(f *frame) {
+		if err := writeFrame(stdinW, f); err != nil {
+			t.Fatalf("writeFrame: %v", err)
+		}
+	}
+	recv = bufio.NewReader(stdoutR)
+	stop = func() {
+		stdinW.Close()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("RunSupervisor did not return after its stdin closed")
+		}
+	}
+	return send, recv, stop
+}
+
+func TestRunSupervisorExecEchoesOutputAndExitCode(t *testing.T) {
+	send, recv, stop := newSupervisorHarness(t)
+	defer stop()
+
+	send(&frame{ID: "1", Kind: frameExec, Command: "echo hello"})
+
+	var stdout []byte
+	for {
+		f, err := readFrame(recv)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		if f.Kind == frameError {
+			t.Fatalf("supervisor reported an error: %s", f.Message)
+		}
+		if f.Kind == frameStdout {
+			stdout = append(stdout, f.Data...)
+		}
+		if f.Kind == frameExit {
+			if f.ExitCode != 0 {
+				t.Fatalf("ExitCode = %d, want 0", f.ExitCode)
+			}
+			break
+		}
+	}
+
+	if string(stdout) != "hello\n" {
+		t.Fatalf("stdout = %q, want %q", stdout, "hello\n")
+	}
+}
+
+func TestRunSupervisorExecReportsNonZeroExitCode(t *testing.T) {
+	send, recv, stop := newSupervisorHarness(t)
+	defer stop()
+
+	send(&frame{ID: "1", Kind: frameExec, Command: "exit 3"})
+
+	exit := readFrameUntil(t, recv, frameExit)
+	if exit.ExitCode != 3 {
+		t.Fatalf("ExitCode = %d, want 3", exit.ExitCode)
+	}
+}
+
+func TestRunSupervisorExecPipesStdin(t *testing.T) {
+	send, recv, stop := newSupervisorHarness(t)
+	defer stop()
+
+	send(&frame{ID: "1", Kind: frameExec, Command: "cat", Stdin: []byte("ping")})
+
+	var stdout []byte
+	for {
+		f, err := readFrame(recv)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		if f.Kind == frameError {
+			t.Fatalf("supervisor reported an error: %s", f.Message)
+		}
+		if f.Kind == frameStdout {
+			stdout = append(stdout, f.Data...)
+		}
+		if f.Kind == frameExit {
+			break
+		}
+	}
+
+	if string(stdout) != "ping" {
+		t.Fatalf("stdout = %q, want %q", stdout, "ping")
+	}
+}
+
+func TestRunSupervisorHandlesConcurrentExecsIndependently(t *testing.T) {
+	send, recv, stop := newSupervisorHarness(t)
+	defer stop()
+
+	send(&frame{ID: "a", Kind: frameExec, Command: "echo from-a"})
+	send(&frame{ID: "b", Kind: frameExec, Command: "echo from-b"})
+
+	got := map[string]string{}
+	exited := map[string]bool{}
+	for len(exited) < 2 {
+		f, err := readFrame(recv)
+		if err != nil {
+			t.Fatalf("readFrame: %v", err)
+		}
+		if f.Kind == frameError {
+			t.Fatalf("supervisor reported an error for %s: %s", f.ID, f.Message)
+		}
+		if f.Kind == frameStdout {
+			got[f.ID] += string(f.Data)
+		}
+		if f.Kind == frameExit {
+			exited[f.ID] = true
+		}
+	}
+
+	if got["a"] != "from-a\n" {
+		t.Errorf("exec a stdout = %q, want %q", got["a"], "from-a\n")
+	}
+	if got["b"] != "from-b\n" {
+		t.Errorf("exec b stdout = %q, want %q", got["b"], "from-b\n")
+	}
+}
+
+func TestRunSupervisorReturnsWhenStdinCloses(t *testing.T) {
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	defer stdoutR.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- RunSupervisor(stdinR, stdoutW) }()
+
+	stdinW.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunSupervisor returned an error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunSupervisor did not return once its stdin closed")
+	}
+}
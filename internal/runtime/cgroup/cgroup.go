@@ -0,0 +1,196 @@
+// Package cgroup provides a thin wrapper around cgroup v2 controller files
+// for applying resource limits to sandboxes and reading back live usage.
+// It assumes a unified cgroup v2 hierarchy mounted at /sys/fs/cgroup, which
+// is the default on any reasonably current Linux distribution.
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Limits describes the resource limits to apply to a cgroup. A zero value
+// for any field leaves that controller unconstrained.
+type Limits struct {
+	// CPUWeight maps to cpu.weight (1-10000, proportional share of CPU time).
+	CPUWeight int64
+	// CPUQuotaUs and CPUPeriodUs map to cpu.max as "<quota> <period>". A zero
+	// quota leaves CPU time unlimited.
+	CPUQuotaUs  int64
+	CPUPeriodUs int64
+	// MemoryLimitBytes maps to memory.max.
+	MemoryLimitBytes int64
+	// PidsMax maps to pids.max.
+	PidsMax int64
+	// IOWeight maps to io.weight (1-10000).
+	IOWeight int64
+}
+
+// Create makes a new cgroup directory named name under parent and returns
+// its path. parent must already exist (callers typically create it once at
+// startup, e.g. /sys/fs/cgroup/sandbox-rls.slice).
+func Create(parent, name string) (string, error) {
+	path := filepath.Join(parent, name)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", fmt.Errorf("cgroup: failed to create %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// Apply writes limits to the controller files under path. Fields left at
+// their zero value are skipped so the controller's default (unlimited)
+// applies.
+func Apply(path string, limits Limits) error {
+	if limits.CPUWeight > 0 {
+		if err := writeFile(path, "cpu.weight", strconv.FormatInt(limits.CPUWeight, 10)); err != nil {
+			return err
+		}
+	}
+	if limits.CPUQuotaUs > 0 {
+		period := limits.CPUPeriodUs
+		if period <= 0 {
+			period = 100000
+		}
+		value := fmt.Sprintf("%d %d", limits.CPUQuotaUs, period)
+		if err := writeFile(path, "cpu.max", value); err != nil {
+			return err
+		}
+	}
+	if limits.MemoryLimitBytes > 0 {
+		if err := writeFile(path, "memory.max", strconv.FormatInt(limits.MemoryLimitBytes, 10)); err != nil {
+			return err
+		}
+	}
+	if limits.PidsMax > 0 {
+		if err := writeFile(path, "pids.max", strconv.FormatInt(limits.PidsMax, 10)); err != nil {
+			return err
+		}
+	}
+	if limits.IOWeight > 0 {
+		if err := writeFile(path, "io.weight", strconv.FormatInt(limits.IOWeight, 10)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProcess moves pid into the cgroup at path by writing to cgroup.procs.
+func AddProcess(path string, pid int) error {
+	return writeFile(path, "cgroup.procs", strconv.Itoa(pid))
+}
+
+// removeRetries and removeRetryDelay bound how long Remove keeps retrying an
+// EBUSY rmdir before giving up.
+const (
+	removeRetries    = 20
+	removeRetryDelay = 10 * time.Millisecond
+)
+
+// Remove deletes the cgroup directory at path. The kernel refuses to remove
+// a cgroup that still has member processes, so callers must kill/reap
+// everything in it first. Even after the caller's own direct child has been
+// reaped, further descendants it spawned (e.g. execs run inside the
+// sandbox) may take the kernel a few more scheduler ticks to fully exit, so
+// an immediately-following rmdir can still see EBUSY; Remove retries briefly
+// rather than surfacing that as a caller-visible error.
+func Remove(path string) error {
+	var err error
+	for attempt := 0; attempt < removeRetries; attempt++ {
+		err = os.Remove(path)
+		if err == nil || os.IsNotExist(err) {
+			return nil
+		}
+		if !errors.Is(err, syscall.EBUSY) {
+			break
+		}
+		time.Sleep(removeRetryDelay)
+	}
+	return fmt.Errorf("cgroup: failed to remove %s: %w", path, err)
+}
+
+// Stats holds a point-in-time snapshot of a cgroup's resource usage.
+type Stats struct {
+	CPUUsageUsec       uint64
+	MemoryCurrentBytes uint64
+	MemoryPeakBytes    uint64
+	PIDs               int
+}
+
+// ReadStats reads cpu.stat, memory.current, memory.peak, and
+// pids.current from the cgroup at path.
+func ReadStats(path string) (*Stats, error) {
+	cpuUsage, err := readCPUUsageUsec(path)
+	if err != nil {
+		return nil, err
+	}
+
+	memCurrent, err := readUintFile(path, "memory.current")
+	if err != nil {
+		return nil, err
+	}
+
+	// memory.peak was only added in relatively recent kernels; treat its
+	// absence as "unknown" rather than an error.
+	memPeak, err := readUintFile(path, "memory.peak")
+	if err != nil {
+		memPeak = memCurrent
+	}
+
+	pids, err := readUintFile(path, "pids.current")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		CPUUsageUsec:       cpuUsage,
+		MemoryCurrentBytes: memCurrent,
+		MemoryPeakBytes:    memPeak,
+		PIDs:               int(pids),
+	}, nil
+}
+
+// readCPUUsageUsec extracts the "usage_usec" field from cpu.stat, which is
+// a small key/value text file rather than a single integer.
+func readCPUUsageUsec(path string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(path, "cpu.stat"))
+	if err != nil {
+		return 0, fmt.Errorf("cgroup: failed to read cpu.stat: %w", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "usage_usec" {
+			usage, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("cgroup: invalid usage_usec in cpu.stat: %w", err)
+			}
+			return usage, nil
+		}
+	}
+	return 0, fmt.Errorf("cgroup: usage_usec not found in cpu.stat")
+}
+
+func readUintFile(path, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(path, name))
+	if err != nil {
+		return 0, fmt.Errorf("cgroup: failed to read %s: %w", name, err)
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cgroup: invalid value in %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func writeFile(path, name, value string) error {
+	if err := os.WriteFile(filepath.Join(path, name), []byte(value), 0o644); err != nil {
+		return fmt.Errorf("cgroup: failed to write %s: %w", name, err)
+	}
+	return nil
+}
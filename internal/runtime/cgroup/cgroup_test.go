@@ -0,0 +1,182 @@
+package cgroup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestApplyWritesOnlyNonZeroFields(t *testing.T) {
+	dir := t.TempDir()
+	// writeFile requires the named controller file to already exist under
+	// a real cgroupfs; a plain directory has none, so only create the
+	// ones Apply should actually touch.
+	for _, name := range []string{"cpu.weight", "cpu.max"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("seeding %s: %v", name, err)
+		}
+	}
+
+	limits := Limits{CPUWeight: 500, CPUQuotaUs: 50000}
+	if err := Apply(dir, limits); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	weight, err := os.ReadFile(filepath.Join(dir, "cpu.weight"))
+	if err != nil || string(weight) != "500" {
+		t.Fatalf("cpu.weight = %q, err %v, want \"500\"", weight, err)
+	}
+
+	max, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	if err != nil || string(max) != "50000 100000" {
+		t.Fatalf("cpu.max = %q, err %v, want \"50000 100000\" (default period)", max, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "memory.max")); !os.IsNotExist(err) {
+		t.Fatalf("memory.max should not have been written when MemoryLimitBytes is zero, stat err = %v", err)
+	}
+}
+
+func TestApplyHonorsExplicitPeriod(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), nil, 0o644); err != nil {
+		t.Fatalf("seeding cpu.max: %v", err)
+	}
+
+	limits := Limits{CPUQuotaUs: 20000, CPUPeriodUs: 40000}
+	if err := Apply(dir, limits); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	max, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	if err != nil || string(max) != "20000 40000" {
+		t.Fatalf("cpu.max = %q, err %v, want \"20000 40000\"", max, err)
+	}
+}
+
+func TestReadStatsParsesCPUStatAndFallsBackForMissingPeak(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cpu.stat":       "usage_usec 123456\nuser_usec 100000\nsystem_usec 23456\n",
+		"memory.current": "1048576\n",
+		"pids.current":   "7\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("seeding %s: %v", name, err)
+		}
+	}
+
+	stats, err := ReadStats(dir)
+	if err != nil {
+		t.Fatalf("ReadStats: %v", err)
+	}
+	if stats.CPUUsageUsec != 123456 {
+		t.Errorf("CPUUsageUsec = %d, want 123456", stats.CPUUsageUsec)
+	}
+	if stats.MemoryCurrentBytes != 1048576 {
+		t.Errorf("MemoryCurrentBytes = %d, want 1048576", stats.MemoryCurrentBytes)
+	}
+	// memory.peak wasn't written, so it should fall back to memory.current.
+	if stats.MemoryPeakBytes != stats.MemoryCurrentBytes {
+		t.Errorf("MemoryPeakBytes = %d, want fallback to MemoryCurrentBytes (%d)", stats.MemoryPeakBytes, stats.MemoryCurrentBytes)
+	}
+	if stats.PIDs != 7 {
+		t.Errorf("PIDs = %d, want 7", stats.PIDs)
+	}
+}
+
+func TestReadStatsUsesMemoryPeakWhenPresent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cpu.stat":       "usage_usec 1\n",
+		"memory.current": "100\n",
+		"memory.peak":    "200\n",
+		"pids.current":   "1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("seeding %s: %v", name, err)
+		}
+	}
+
+	stats, err := ReadStats(dir)
+	if err != nil {
+		t.Fatalf("ReadStats: %v", err)
+	}
+	if stats.MemoryPeakBytes != 200 {
+		t.Errorf("MemoryPeakBytes = %d, want 200", stats.MemoryPeakBytes)
+	}
+}
+
+func TestReadStatsErrorsWhenUsageUsecMissing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("user_usec 1\n"), 0o644); err != nil {
+		t.Fatalf("seeding cpu.stat: %v", err)
+	}
+
+	if _, err := ReadStats(dir); err == nil {
+		t.Fatal("ReadStats did not error when usage_usec is absent from cpu.stat")
+	}
+}
+
+func TestRemoveSucceedsImmediatelyOnEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "cg")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	if err := Remove(sub); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(sub); !os.IsNotExist(err) {
+		t.Fatalf("cgroup dir still exists after Remove, stat err = %v", err)
+	}
+}
+
+func TestRemoveIsIdempotentWhenAlreadyGone(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "does-not-exist")
+
+	if err := Remove(missing); err != nil {
+		t.Fatalf("Remove on an already-absent path returned an error: %v", err)
+	}
+}
+
+// TestRemoveDoesNotRetryNonEBUSYErrors confirms Remove treats a non-EBUSY
+// rmdir failure (e.g. ENOTEMPTY, which a plain non-empty directory
+// reproduces without needing real cgroupfs) as terminal instead of
+// burning through all removeRetries, which would make every Destroy call
+// on a genuinely-stuck cgroup block for removeRetries*removeRetryDelay.
+func TestRemoveDoesNotRetryNonEBUSYErrors(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "cg")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "blocker"), nil, 0o644); err != nil {
+		t.Fatalf("seeding blocker file: %v", err)
+	}
+
+	start := time.Now()
+	err := Remove(sub)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Remove succeeded despite the directory never becoming empty")
+	}
+	if errors.Is(err, syscall.EBUSY) {
+		t.Fatalf("Remove's ENOTEMPTY case should not be misreported as EBUSY: %v", err)
+	}
+	if !strings.Contains(err.Error(), sub) {
+		t.Fatalf("error %q does not mention the path %q", err, sub)
+	}
+	if elapsed >= removeRetryDelay {
+		t.Fatalf("Remove took %v on a non-EBUSY error, want it to give up on the first attempt (< %v)", elapsed, removeRetryDelay)
+	}
+}
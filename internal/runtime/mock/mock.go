@@ -3,6 +3,7 @@ package mock
 
 import (
 	"context"
+	"io"
 	"sync"
 	"time"
 
@@ -197,5 +198,85 @@ func (m *MockRuntime) ExecStream(ctx context.Context, sandboxID string, req *typ
 	return nil
 }
 
+// Stats returns a deterministic, fixed stats snapshot for testing.
+func (m *MockRuntime) Stats(ctx context.Context, sandboxID string) (*types.SandboxStats, error) {
+	m.mu.RLock()
+	sandbox, ok := m.sandboxes[sandboxID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, types.ErrSandboxNotFound
+	}
+
+	if sandbox.Status != types.StatusRunning {
+		return nil, types.ErrNotRunning
+	}
+
+	return &types.SandboxStats{
+		CPUUsageUsec:       1000,
+		MemoryCurrentBytes: 1 << 20,
+		MemoryPeakBytes:    2 << 20,
+		PIDs:               1,
+		Timestamp:          time.Now(),
+	}, nil
+}
+
+// StatsStream sends a single deterministic snapshot and closes output.
+func (m *MockRuntime) StatsStream(ctx context.Context, sandboxID string, output chan<- *types.SandboxStats) error {
+	defer close(output)
+
+	stats, err := m.Stats(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case output <- stats:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// ExecInteractive echoes tty's input back to it until ctx is done or tty
+// reaches EOF, so tests can exercise interactive exec without a real pty.
+func (m *MockRuntime) ExecInteractive(ctx context.Context, sandboxID string, req *types.ExecRequest, tty runtime.TTYStream) error {
+	m.mu.RLock()
+	sandbox, ok := m.sandboxes[sandboxID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+	if sandbox.Status != types.StatusRunning {
+		return types.ErrNotRunning
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		n, err := tty.Read(buf)
+		if n > 0 {
+			if _, werr := tty.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // Verify interface compliance at compile time
 var _ runtime.RuntimeWithExecutor = (*MockRuntime)(nil)
+
+func init() {
+	runtime.Register("mock", func(config interface{}) (runtime.RuntimeWithExecutor, error) {
+		return New(), nil
+	})
+}
@@ -0,0 +1,101 @@
+// Package pty allocates pseudo-terminal pairs and forwards terminal
+// resizes for runtime backends that attach a pty to a sandboxed process's
+// stdio (bwrap, runsc) to support RuntimeWithExecutor.ExecInteractive.
+package pty
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Pair is an open pty pair. Master is kept by the runtime to pump bytes
+// and apply resizes; Slave is handed to the child process as its stdio.
+type Pair struct {
+	Master *os.File
+	Slave  *os.File
+}
+
+// Open allocates a new pty pair via /dev/ptmx.
+func Open() (*Pair, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("pty: open /dev/ptmx: %w", err)
+	}
+
+	// Unlock the slave (TIOCSPTLCK) and look up its number (TIOCGPTN) by
+	// hand: this version of x/sys/unix doesn't expose Unlockpt/Ptsname.
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pty: unlock slave: %w", err)
+	}
+
+	n, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pty: get slave number: %w", err)
+	}
+	name := fmt.Sprintf("/dev/pts/%d", n)
+
+	slave, err := os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pty: open slave %s: %w", name, err)
+	}
+
+	return &Pair{Master: master, Slave: slave}, nil
+}
+
+// Resize sets the pty's window size; the kernel delivers SIGWINCH to the
+// pty's foreground process group as a result.
+func (p *Pair) Resize(cols, rows uint16) error {
+	return unix.IoctlSetWinsize(int(p.Master.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+		Row: rows,
+		Col: cols,
+	})
+}
+
+// Close closes both ends of the pair.
+func (p *Pair) Close() error {
+	err := p.Master.Close()
+	if serr := p.Slave.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// ForwardHostResize watches this process's own SIGWINCH, which the kernel
+// raises when its controlling terminal is resized, and applies the new
+// size via apply, mirroring it to tty so a caller that tracks size (e.g.
+// to answer a ResizePty RPC) stays in sync. It returns a function that
+// stops watching; callers should defer it once the exec they started it
+// for has finished.
+func ForwardHostResize(apply func(cols, rows uint16), tty interface {
+	Resize(cols, rows uint16) error
+}) func() {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-winch:
+				if ws, err := unix.IoctlGetWinsize(unix.Stdin, unix.TIOCGWINSZ); err == nil {
+					apply(ws.Col, ws.Row)
+					_ = tty.Resize(ws.Col, ws.Row)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(winch)
+		close(done)
+	}
+}
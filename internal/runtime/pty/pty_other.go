@@ -0,0 +1,39 @@
+//go:build !linux
+
+package pty
+
+import (
+	"fmt"
+	"os"
+)
+
+// Pair mirrors the Linux Pair but pty allocation isn't implemented on this
+// platform; BwrapRuntime falls back to its non-Linux compatibility mode
+// (plain pipes, no pty) instead.
+type Pair struct {
+	Master *os.File
+	Slave  *os.File
+}
+
+// Open always fails on this platform.
+func Open() (*Pair, error) {
+	return nil, fmt.Errorf("pty: not supported on this platform")
+}
+
+// Resize always fails on this platform.
+func (p *Pair) Resize(cols, rows uint16) error {
+	return fmt.Errorf("pty: not supported on this platform")
+}
+
+// Close is a no-op on this platform.
+func (p *Pair) Close() error {
+	return nil
+}
+
+// ForwardHostResize is a no-op on this platform since there is no pty to
+// resize; it returns a function that does nothing.
+func ForwardHostResize(apply func(cols, rows uint16), tty interface {
+	Resize(cols, rows uint16) error
+}) func() {
+	return func() {}
+}
@@ -0,0 +1,717 @@
+// Package runsc provides a sandbox runtime implementation backed by gVisor's
+// runsc, a user-space kernel that intercepts syscalls instead of relying
+// solely on Linux namespaces. It trades some of bwrap's raw performance for
+// a stronger isolation boundary on hosts where that matters (e.g. kernels
+// with restricted or disabled user namespaces).
+package runsc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	rt "github.com/ajaxzhan/sandbox-rls/internal/runtime"
+	"github.com/ajaxzhan/sandbox-rls/internal/runtime/pty"
+	"github.com/ajaxzhan/sandbox-rls/pkg/types"
+)
+
+// Config holds configuration for the RunscRuntime.
+type Config struct {
+	// RunscPath is the path to the runsc binary (default: "runsc").
+	RunscPath string
+
+	// BundleDir is the base directory under which per-sandbox OCI bundles
+	// (rootfs + config.json) are generated.
+	BundleDir string
+
+	// DefaultTimeout is the default timeout for Exec operations.
+	DefaultTimeout time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		RunscPath:      "runsc",
+		BundleDir:      "/tmp/sandbox-rls/runsc",
+		DefaultTimeout: 30 * time.Second,
+	}
+}
+
+// sandboxState holds internal state for a sandbox.
+type sandboxState struct {
+	sandbox    *types.Sandbox
+	config     *rt.SandboxConfig
+	bundlePath string
+
+	// interactiveResize resizes the pty of this sandbox's in-flight
+	// ExecInteractive call, if any; set for the duration of that call so
+	// ResizeExec has something to reach. See runtime.ExecResizer.
+	interactiveResize func(cols, rows uint16) error
+}
+
+// RunscRuntime implements runtime.RuntimeWithExecutor using gVisor's runsc.
+type RunscRuntime struct {
+	mu     sync.RWMutex
+	config *Config
+	states map[string]*sandboxState
+}
+
+// New creates a new RunscRuntime with the given configuration.
+func New(config *Config) *RunscRuntime {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &RunscRuntime{
+		config: config,
+		states: make(map[string]*sandboxState),
+	}
+}
+
+// Name returns the name of this runtime implementation.
+func (r *RunscRuntime) Name() string {
+	return "runsc"
+}
+
+// Create generates an OCI bundle for the sandbox but does not start it.
+func (r *RunscRuntime) Create(ctx context.Context, config *rt.SandboxConfig) (*types.Sandbox, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("sandbox ID is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.states[config.ID]; exists {
+		return nil, fmt.Errorf("sandbox %s already exists", config.ID)
+	}
+
+	if config.CodebasePath != "" {
+		if _, err := os.Stat(config.CodebasePath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("codebase path does not exist: %s", config.CodebasePath)
+		}
+	}
+
+	if config.Resources != (rt.Resources{}) {
+		return nil, fmt.Errorf("runsc: Resources limits are not yet supported by this backend")
+	}
+	if !securityProfileIsZero(config.SecurityProfile) {
+		return nil, fmt.Errorf("runsc: SecurityProfile is not yet supported by this backend")
+	}
+
+	bundlePath := filepath.Join(r.config.BundleDir, config.ID)
+	if err := r.writeBundle(bundlePath, config); err != nil {
+		return nil, fmt.Errorf("failed to write OCI bundle: %w", err)
+	}
+
+	if err := r.runsc(ctx, "create", "--bundle", bundlePath, config.ID); err != nil {
+		return nil, fmt.Errorf("runsc create failed: %w", err)
+	}
+
+	sandbox := &types.Sandbox{
+		ID:          config.ID,
+		CodebaseID:  config.CodebaseID,
+		Permissions: config.Permissions,
+		Status:      types.StatusPending,
+		Labels:      config.Labels,
+		CreatedAt:   time.Now(),
+		MountPoint:  config.MountPoint,
+	}
+
+	r.states[config.ID] = &sandboxState{
+		sandbox:    sandbox,
+		config:     config,
+		bundlePath: bundlePath,
+	}
+
+	return sandbox, nil
+}
+
+// Start starts a previously created sandbox via `runsc start`.
+func (r *RunscRuntime) Start(ctx context.Context, sandboxID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[sandboxID]
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+
+	if state.sandbox.Status == types.StatusRunning {
+		return types.ErrAlreadyRunning
+	}
+
+	if err := r.runsc(ctx, "start", sandboxID); err != nil {
+		return fmt.Errorf("runsc start failed: %w", err)
+	}
+
+	state.sandbox.Status = types.StatusRunning
+	now := time.Now()
+	state.sandbox.StartedAt = &now
+
+	return nil
+}
+
+// Stop stops a running sandbox by killing its init process.
+func (r *RunscRuntime) Stop(ctx context.Context, sandboxID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[sandboxID]
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+
+	if state.sandbox.Status != types.StatusRunning {
+		return types.ErrNotRunning
+	}
+
+	if err := r.runsc(ctx, "kill", sandboxID, "SIGKILL"); err != nil {
+		return fmt.Errorf("runsc kill failed: %w", err)
+	}
+
+	state.sandbox.Status = types.StatusStopped
+	now := time.Now()
+	state.sandbox.StoppedAt = &now
+
+	return nil
+}
+
+// Destroy tears down the container and removes its bundle.
+func (r *RunscRuntime) Destroy(ctx context.Context, sandboxID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[sandboxID]
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+
+	_ = r.runsc(ctx, "kill", sandboxID, "SIGKILL")
+	if err := r.runsc(ctx, "delete", "--force", sandboxID); err != nil {
+		return fmt.Errorf("runsc delete failed: %w", err)
+	}
+
+	_ = os.RemoveAll(state.bundlePath)
+	delete(r.states, sandboxID)
+	return nil
+}
+
+// Get retrieves information about a sandbox.
+func (r *RunscRuntime) Get(ctx context.Context, sandboxID string) (*types.Sandbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.states[sandboxID]
+	if !ok {
+		return nil, types.ErrSandboxNotFound
+	}
+
+	sandbox := *state.sandbox
+	return &sandbox, nil
+}
+
+// List returns all sandboxes managed by this runtime.
+func (r *RunscRuntime) List(ctx context.Context) ([]*types.Sandbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*types.Sandbox, 0, len(r.states))
+	for _, state := range r.states {
+		sandbox := *state.sandbox
+		result = append(result, &sandbox)
+	}
+	return result, nil
+}
+
+// setInteractiveResize records resize as the way to reach the pty of
+// state's current interactive exec, for ResizeExec to call; pass nil when
+// that exec ends so a later ResizeExec call fails instead of resizing a
+// pty that's gone.
+func (r *RunscRuntime) setInteractiveResize(state *sandboxState, resize func(cols, rows uint16) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state.interactiveResize = resize
+}
+
+// ResizeExec implements runtime.ExecResizer by resizing the pty of
+// sandboxID's in-flight ExecInteractive call, if any.
+func (r *RunscRuntime) ResizeExec(ctx context.Context, sandboxID string, cols, rows uint16) error {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	var resize func(cols, rows uint16) error
+	if ok {
+		resize = state.interactiveResize
+	}
+	r.mu.RUnlock()
+	if !ok {
+		return types.ErrSandboxNotFound
+	}
+	if resize == nil {
+		return fmt.Errorf("runsc: no interactive exec in progress for sandbox %s", sandboxID)
+	}
+	return resize(cols, rows)
+}
+
+// Exec runs a command inside the sandbox via `runsc exec`.
+func (r *RunscRuntime) Exec(ctx context.Context, sandboxID string, req *types.ExecRequest) (*types.ExecResult, error) {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	if !ok {
+		r.mu.RUnlock()
+		return nil, types.ErrSandboxNotFound
+	}
+	if state.sandbox.Status != types.StatusRunning {
+		r.mu.RUnlock()
+		return nil, types.ErrNotRunning
+	}
+	r.mu.RUnlock()
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = r.config.DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	cmd := r.buildExecCommand(ctx, sandboxID, req)
+
+	stdout, err := cmd.Output()
+	duration := time.Since(start)
+
+	result := &types.ExecResult{Duration: duration}
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, types.ErrTimeout
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+			result.Stderr = string(exitErr.Stderr)
+			result.Stdout = string(stdout)
+			return result, nil
+		}
+		return nil, fmt.Errorf("exec failed: %w", err)
+	}
+
+	result.Stdout = string(stdout)
+	result.ExitCode = 0
+	return result, nil
+}
+
+// ExecStream executes a command and streams its stdout.
+func (r *RunscRuntime) ExecStream(ctx context.Context, sandboxID string, req *types.ExecRequest, output chan<- []byte) error {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	if !ok {
+		r.mu.RUnlock()
+		close(output)
+		return types.ErrSandboxNotFound
+	}
+	if state.sandbox.Status != types.StatusRunning {
+		r.mu.RUnlock()
+		close(output)
+		return types.ErrNotRunning
+	}
+	r.mu.RUnlock()
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = r.config.DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := r.buildExecCommand(ctx, sandboxID, req)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		close(output)
+		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		close(output)
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	go func() {
+		defer close(output)
+		buf := make([]byte, 4096)
+		for {
+			n, err := stdout.Read(buf)
+			if n > 0 {
+				data := make([]byte, n)
+				copy(data, buf[:n])
+				select {
+				case output <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return cmd.Wait()
+}
+
+// ExecInteractive runs req (which should set TTY) inside the sandbox via
+// `runsc exec` with a pty attached, copying bytes between it and tty until
+// the command exits or ctx is done.
+func (r *RunscRuntime) ExecInteractive(ctx context.Context, sandboxID string, req *types.ExecRequest, tty rt.TTYStream) error {
+	r.mu.RLock()
+	state, ok := r.states[sandboxID]
+	if !ok {
+		r.mu.RUnlock()
+		return types.ErrSandboxNotFound
+	}
+	if state.sandbox.Status != types.StatusRunning {
+		r.mu.RUnlock()
+		return types.ErrNotRunning
+	}
+	r.mu.RUnlock()
+
+	// Unlike Exec/ExecStream, an interactive session has no natural
+	// duration, so only bound it if the caller asked for that explicitly.
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	pair, err := pty.Open()
+	if err != nil {
+		return fmt.Errorf("failed to allocate pty: %w", err)
+	}
+	defer pair.Close()
+
+	cols, rows := req.Cols, req.Rows
+	if cols == 0 {
+		cols = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	if err := pair.Resize(cols, rows); err != nil {
+		return fmt.Errorf("failed to set initial pty size: %w", err)
+	}
+
+	r.setInteractiveResize(state, func(cols, rows uint16) error {
+		return pair.Resize(cols, rows)
+	})
+	defer r.setInteractiveResize(state, nil)
+
+	cmd := r.buildExecCommand(ctx, sandboxID, req)
+	cmd.Stdin = pair.Slave
+	cmd.Stdout = pair.Slave
+	cmd.Stderr = pair.Slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+	pair.Slave.Close()
+
+	stopResize := pty.ForwardHostResize(func(cols, rows uint16) {
+		_ = pair.Resize(cols, rows)
+	}, tty)
+	defer stopResize()
+
+	go io.Copy(pair.Master, tty)
+
+	copyDone := make(chan struct{})
+	go func() {
+		defer close(copyDone)
+		_, _ = io.Copy(tty, pair.Master)
+	}()
+
+	err = cmd.Wait()
+	pair.Master.Close()
+	<-copyDone
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return types.ErrTimeout
+	}
+	return err
+}
+
+// runscStatsEvent is the subset of `runsc events --stats` output this
+// package parses.
+type runscStatsEvent struct {
+	Data struct {
+		CPU struct {
+			Usage struct {
+				Total uint64 `json:"total"`
+			} `json:"usage"`
+		} `json:"cpu"`
+		Memory struct {
+			Usage struct {
+				Usage uint64 `json:"usage"`
+				Max   uint64 `json:"max"`
+			} `json:"usage"`
+		} `json:"memory"`
+		Pids struct {
+			Current int `json:"current"`
+		} `json:"pids"`
+	} `json:"data"`
+}
+
+// Stats returns a point-in-time snapshot of the sandbox's resource usage by
+// asking runsc for a single stats event.
+func (r *RunscRuntime) Stats(ctx context.Context, sandboxID string) (*types.SandboxStats, error) {
+	r.mu.RLock()
+	_, ok := r.states[sandboxID]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, types.ErrSandboxNotFound
+	}
+
+	out, err := exec.CommandContext(ctx, r.config.RunscPath, "events", "--stats", sandboxID).Output()
+	if err != nil {
+		return nil, fmt.Errorf("runsc events failed: %w", err)
+	}
+
+	var event runscStatsEvent
+	if err := json.Unmarshal(out, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse runsc stats: %w", err)
+	}
+
+	return &types.SandboxStats{
+		CPUUsageUsec:       event.Data.CPU.Usage.Total / 1000, // ns -> usec
+		MemoryCurrentBytes: event.Data.Memory.Usage.Usage,
+		MemoryPeakBytes:    event.Data.Memory.Usage.Max,
+		PIDs:               event.Data.Pids.Current,
+		Timestamp:          time.Now(),
+	}, nil
+}
+
+// statsPollInterval is how often StatsStream asks runsc for a new snapshot.
+const statsPollInterval = time.Second
+
+// StatsStream sends a stats snapshot on output once per statsPollInterval
+// until ctx is done, then closes output.
+func (r *RunscRuntime) StatsStream(ctx context.Context, sandboxID string, output chan<- *types.SandboxStats) error {
+	defer close(output)
+
+	ticker := time.NewTicker(statsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := r.Stats(ctx, sandboxID)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case output <- stats:
+		case <-ctx.Done():
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// buildExecCommand builds a `runsc exec` invocation for req.
+func (r *RunscRuntime) buildExecCommand(ctx context.Context, sandboxID string, req *types.ExecRequest) *exec.Cmd {
+	args := []string{"exec"}
+	for k, v := range req.Env {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", k, v))
+	}
+	if req.WorkDir != "" {
+		args = append(args, "--cwd", req.WorkDir)
+	}
+	args = append(args, sandboxID, "/bin/sh", "-c", req.Command)
+
+	cmd := exec.CommandContext(ctx, r.config.RunscPath, args...)
+	if req.Stdin != "" {
+		cmd.Stdin = strings.NewReader(req.Stdin)
+	}
+	return cmd
+}
+
+// runsc invokes the runsc binary with the given arguments.
+func (r *RunscRuntime) runsc(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, r.config.RunscPath, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w", string(out), err)
+	}
+	return nil
+}
+
+// ociSpec is the minimal subset of the OCI runtime-spec config.json that
+// runsc needs to create a container: the process to run, the rootfs, mounts,
+// and the namespaces/network mode to apply.
+type ociSpec struct {
+	OCIVersion string     `json:"ociVersion"`
+	Process    ociProcess `json:"process"`
+	Root       ociRoot    `json:"root"`
+	Mounts     []ociMount `json:"mounts,omitempty"`
+	Linux      ociLinux   `json:"linux"`
+}
+
+type ociProcess struct {
+	Terminal bool     `json:"terminal"`
+	Args     []string `json:"args"`
+	Cwd      string   `json:"cwd"`
+	Env      []string `json:"env,omitempty"`
+}
+
+type ociRoot struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly"`
+}
+
+type ociMount struct {
+	Destination string   `json:"destination"`
+	Source      string   `json:"source"`
+	Type        string   `json:"type"`
+	Options     []string `json:"options,omitempty"`
+}
+
+type ociLinux struct {
+	Namespaces []ociNamespace `json:"namespaces"`
+}
+
+type ociNamespace struct {
+	Type string `json:"type"`
+}
+
+// writeBundle generates an OCI bundle (rootfs symlink + config.json) for
+// config under bundlePath.
+// baseRootDirs are read-only bound from the host into every rootfs so the
+// sandboxed process has a real userland to exec into — mirrors bwrap's own
+// --ro-bind list in buildSupervisorCommand. Without these the generated
+// rootfs is an empty directory and even the default "/bin/sh" Args can't
+// be found, let alone run.
+var baseRootDirs = []string{"/usr", "/bin", "/lib", "/lib64", "/sbin"}
+
+func (r *RunscRuntime) writeBundle(bundlePath string, config *rt.SandboxConfig) error {
+	if err := os.MkdirAll(bundlePath, 0o755); err != nil {
+		return err
+	}
+
+	rootfs := filepath.Join(bundlePath, "rootfs")
+	if err := os.MkdirAll(rootfs, 0o755); err != nil {
+		return err
+	}
+
+	workdir := "/workspace"
+	if config.MountPoint != "" {
+		workdir = config.MountPoint
+	}
+
+	mounts := []ociMount{
+		{Destination: "/proc", Type: "proc", Source: "proc"},
+		{Destination: "/dev", Type: "tmpfs", Source: "tmpfs", Options: []string{"nosuid", "strictatime", "mode=755", "size=65536k"}},
+	}
+	for _, dir := range baseRootDirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		mounts = append(mounts, ociMount{
+			Destination: dir,
+			Source:      dir,
+			Type:        "bind",
+			Options:     []string{"rbind", "ro"},
+		})
+	}
+	if config.CodebasePath != "" {
+		mounts = append(mounts, ociMount{
+			Destination: workdir,
+			Source:      config.CodebasePath,
+			Type:        "bind",
+			Options:     []string{"rbind", "rw"},
+		})
+	}
+
+	env := make([]string, 0, len(config.Env))
+	for k, v := range config.Env {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	args := config.Args
+	if len(args) == 0 {
+		args = []string{"/bin/sh"}
+	}
+
+	namespaces := []ociNamespace{
+		{Type: "pid"},
+		{Type: "ipc"},
+		{Type: "uts"},
+		{Type: "mount"},
+	}
+	if !config.EnableNetworking {
+		namespaces = append(namespaces, ociNamespace{Type: "network"})
+	}
+
+	spec := ociSpec{
+		OCIVersion: "1.0.2",
+		Process: ociProcess{
+			Args: args,
+			Cwd:  workdir,
+			Env:  env,
+		},
+		Root: ociRoot{
+			Path:     "rootfs",
+			Readonly: false,
+		},
+		Mounts: mounts,
+		Linux: ociLinux{
+			Namespaces: namespaces,
+		},
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(bundlePath, "config.json"), data, 0o644)
+}
+
+// securityProfileIsZero reports whether profile leaves every field at its
+// zero value. types.SecurityProfile isn't comparable with == (it holds
+// slices), so Create checks this explicitly before refusing an unsupported,
+// non-default profile.
+func securityProfileIsZero(profile types.SecurityProfile) bool {
+	return profile.Name == "" && len(profile.CapDrop) == 0 && len(profile.CapAdd) == 0 && profile.Seccomp == nil
+}
+
+// IsRunscAvailable checks if runsc is available on the system.
+func IsRunscAvailable() bool {
+	_, err := exec.LookPath("runsc")
+	return err == nil
+}
+
+// Verify interface compliance at compile time
+var _ rt.RuntimeWithExecutor = (*RunscRuntime)(nil)
+
+func init() {
+	rt.Register("runsc", func(config interface{}) (rt.RuntimeWithExecutor, error) {
+		cfg, ok := config.(*Config)
+		if config != nil && !ok {
+			return nil, fmt.Errorf("runsc: expected *runsc.Config, got %T", config)
+		}
+		return New(cfg), nil
+	})
+}
@@ -0,0 +1,150 @@
+package runsc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rt "github.com/ajaxzhan/sandbox-rls/internal/runtime"
+)
+
+func readBundleConfig(t *testing.T, bundlePath string) ociSpec {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(bundlePath, "config.json"))
+	if err != nil {
+		t.Fatalf("reading config.json: %v", err)
+	}
+	var spec ociSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("parsing config.json: %v", err)
+	}
+	return spec
+}
+
+func TestWriteBundlePopulatesRootfsMountsFromCodebase(t *testing.T) {
+	r := New(nil)
+	bundle := t.TempDir()
+	codebase := t.TempDir()
+
+	config := &rt.SandboxConfig{
+		MountPoint:   "/workspace",
+		CodebasePath: codebase,
+		Env:          map[string]string{"FOO": "bar"},
+	}
+
+	if err := r.writeBundle(bundle, config); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(bundle, "rootfs")); err != nil || !info.IsDir() {
+		t.Fatalf("rootfs dir missing or not a directory: %v", err)
+	}
+
+	spec := readBundleConfig(t, bundle)
+
+	if spec.Root.Path != "rootfs" {
+		t.Errorf("Root.Path = %q, want \"rootfs\"", spec.Root.Path)
+	}
+	if spec.Process.Cwd != "/workspace" {
+		t.Errorf("Process.Cwd = %q, want \"/workspace\"", spec.Process.Cwd)
+	}
+	if got := []string{"FOO=bar"}; len(spec.Process.Env) != 1 || spec.Process.Env[0] != got[0] {
+		t.Errorf("Process.Env = %v, want %v", spec.Process.Env, got)
+	}
+
+	var foundProc, foundDev, foundCodebase bool
+	for _, m := range spec.Mounts {
+		switch m.Destination {
+		case "/proc":
+			foundProc = m.Type == "proc"
+		case "/dev":
+			foundDev = m.Type == "tmpfs"
+		case "/workspace":
+			foundCodebase = m.Source == codebase && m.Type == "bind"
+		}
+	}
+	if !foundProc {
+		t.Error("no /proc mount in generated config.json")
+	}
+	if !foundDev {
+		t.Error("no /dev tmpfs mount in generated config.json")
+	}
+	if !foundCodebase {
+		t.Errorf("no bind mount of CodebasePath (%s) at /workspace in %+v", codebase, spec.Mounts)
+	}
+}
+
+func TestWriteBundleDefaultsArgsWhenUnset(t *testing.T) {
+	r := New(nil)
+	bundle := t.TempDir()
+
+	if err := r.writeBundle(bundle, &rt.SandboxConfig{}); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+
+	spec := readBundleConfig(t, bundle)
+	if len(spec.Process.Args) != 1 || spec.Process.Args[0] != "/bin/sh" {
+		t.Errorf("Process.Args = %v, want [\"/bin/sh\"]", spec.Process.Args)
+	}
+}
+
+func TestWriteBundleNetworkNamespaceTracksEnableNetworking(t *testing.T) {
+	r := New(nil)
+
+	hasNetNS := func(spec ociSpec) bool {
+		for _, ns := range spec.Linux.Namespaces {
+			if ns.Type == "network" {
+				return true
+			}
+		}
+		return false
+	}
+
+	isolated := t.TempDir()
+	if err := r.writeBundle(isolated, &rt.SandboxConfig{EnableNetworking: false}); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+	if !hasNetNS(readBundleConfig(t, isolated)) {
+		t.Error("EnableNetworking=false should add a network namespace to isolate it")
+	}
+
+	networked := t.TempDir()
+	if err := r.writeBundle(networked, &rt.SandboxConfig{EnableNetworking: true}); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+	if hasNetNS(readBundleConfig(t, networked)) {
+		t.Error("EnableNetworking=true should not add a network namespace")
+	}
+}
+
+func TestWriteBundleOnlyBindsBaseRootDirsThatExist(t *testing.T) {
+	r := New(nil)
+	bundle := t.TempDir()
+	existing := t.TempDir()
+
+	orig := baseRootDirs
+	baseRootDirs = []string{existing, filepath.Join(existing, "does-not-exist")}
+	defer func() { baseRootDirs = orig }()
+
+	if err := r.writeBundle(bundle, &rt.SandboxConfig{}); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+
+	spec := readBundleConfig(t, bundle)
+	var foundExisting, foundMissing bool
+	for _, m := range spec.Mounts {
+		if m.Destination == existing {
+			foundExisting = true
+		}
+		if m.Destination == filepath.Join(existing, "does-not-exist") {
+			foundMissing = true
+		}
+	}
+	if !foundExisting {
+		t.Errorf("expected a bind mount for the existing dir %s", existing)
+	}
+	if foundMissing {
+		t.Error("writeBundle bound a base root dir that doesn't exist on the host")
+	}
+}
@@ -0,0 +1,191 @@
+// Package runtime defines the runtime abstraction that sandbox backends
+// (bwrap, runsc, mock, ...) implement, plus a registry so callers can select
+// a backend by name at startup.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ajaxzhan/sandbox-rls/pkg/types"
+)
+
+// SandboxConfig describes how a sandbox should be created.
+type SandboxConfig struct {
+	ID               string
+	CodebaseID       string
+	CodebasePath     string
+	MountPoint       string
+	Permissions      []string
+	Labels           map[string]string
+	Env              map[string]string
+	Args             []string
+	EnableNetworking bool
+	Resources        Resources
+	SecurityProfile  types.SecurityProfile
+}
+
+// Resources describes resource limits to apply to a sandbox. A zero value
+// for any field leaves that resource unconstrained.
+type Resources struct {
+	// CPUShares is a proportional share of CPU time relative to other
+	// sandboxes (maps to cgroup v2's cpu.weight, 1-10000).
+	CPUShares int64
+	// CPUQuotaUs and CPUPeriodUs bound absolute CPU time: the sandbox may
+	// use at most CPUQuotaUs microseconds of CPU per CPUPeriodUs
+	// microseconds (maps to cpu.max). CPUPeriodUs defaults to 100000 if
+	// CPUQuotaUs is set but CPUPeriodUs is not.
+	CPUQuotaUs  int64
+	CPUPeriodUs int64
+	// MemoryLimitBytes caps resident memory (maps to memory.max).
+	MemoryLimitBytes int64
+	// PidsMax caps the number of processes/threads (maps to pids.max).
+	PidsMax int64
+	// IOWeight is a proportional share of block IO bandwidth (maps to
+	// io.weight, 1-10000).
+	IOWeight int64
+}
+
+// Runtime is the minimal lifecycle interface every sandbox backend implements.
+type Runtime interface {
+	// Name returns the identifier this runtime is registered under.
+	Name() string
+
+	Create(ctx context.Context, config *SandboxConfig) (*types.Sandbox, error)
+	Start(ctx context.Context, sandboxID string) error
+	Stop(ctx context.Context, sandboxID string) error
+	Destroy(ctx context.Context, sandboxID string) error
+	Get(ctx context.Context, sandboxID string) (*types.Sandbox, error)
+	List(ctx context.Context) ([]*types.Sandbox, error)
+}
+
+// RuntimeWithExecutor extends Runtime with the ability to run commands
+// inside an already-running sandbox.
+type RuntimeWithExecutor interface {
+	Runtime
+
+	Exec(ctx context.Context, sandboxID string, req *types.ExecRequest) (*types.ExecResult, error)
+	ExecStream(ctx context.Context, sandboxID string, req *types.ExecRequest, output chan<- []byte) error
+
+	// Stats returns a point-in-time snapshot of the sandbox's resource usage.
+	Stats(ctx context.Context, sandboxID string) (*types.SandboxStats, error)
+	// StatsStream sends a snapshot on output at regular intervals until ctx
+	// is done, then closes output.
+	StatsStream(ctx context.Context, sandboxID string, output chan<- *types.SandboxStats) error
+
+	// ExecInteractive runs req (which should set TTY) inside sandboxID with
+	// a pty attached, copying bytes between the sandbox's pty and tty until
+	// the command exits or ctx is done. Unlike Exec/ExecStream, no timeout
+	// is imposed unless req.Timeout is set, since an interactive session's
+	// length is driven by its user, not the caller.
+	ExecInteractive(ctx context.Context, sandboxID string, req *types.ExecRequest, tty TTYStream) error
+}
+
+// TTYStream is a bidirectional byte stream backing an interactive exec:
+// Read supplies terminal input to send to the sandboxed process, and bytes
+// passed to Write are the process's pty output to display. Resize applies
+// a new terminal size to the pty the stream is attached to; implementations
+// typically call it from their own SIGWINCH handling so a caller attached
+// to a real terminal gets live resizing without extra plumbing.
+//
+// ExecInteractive implementations forward Read in a background goroutine
+// for the life of the call; once the exec ends, callers should make Read
+// return (typically by closing the stream's underlying connection) so that
+// goroutine can exit rather than blocking forever on further input.
+type TTYStream interface {
+	io.Reader
+	io.Writer
+	Resize(cols, rows uint16) error
+}
+
+// PidProvider is an optional capability implemented by runtimes that back a
+// sandbox with a single identifiable OS process, e.g. so a caller such as a
+// container-runtime shim can report a pid for task-state queries. Backends
+// that don't map onto a single pid (mock, runsc's sandboxed process tree)
+// need not implement it; callers should type-assert for it and fall back to
+// a synthetic pid when it's absent.
+type PidProvider interface {
+	Pid(ctx context.Context, sandboxID string) (int, error)
+}
+
+// ExecResizer is an optional capability implemented by runtimes that can
+// resize the pty of an in-flight ExecInteractive call directly, given only
+// the sandbox ID. This is for a caller with no controlling terminal of its
+// own to forward SIGWINCH from — e.g. the containerd shim relaying a
+// ResizePty RPC from a remote client — which has no way to reach the pty
+// through the TTYStream it supplied to ExecInteractive, since TTYStream's
+// own Resize flows from the runtime to the caller, not back. Only one
+// interactive exec's size can be driven this way per sandbox at a time.
+type ExecResizer interface {
+	ResizeExec(ctx context.Context, sandboxID string, cols, rows uint16) error
+}
+
+// Factory builds a RuntimeWithExecutor from a backend-specific config value.
+// Backends document the concrete type they expect (e.g. *bwrap.Config);
+// passing nil should yield that backend's defaults.
+type Factory func(config interface{}) (RuntimeWithExecutor, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a runtime backend available under name for later lookup.
+// It is typically called from a backend package's init() function.
+// Register panics if name is empty or already registered, since that
+// indicates a programming error rather than a runtime condition.
+func Register(name string, factory Factory) {
+	if name == "" {
+		panic("runtime: Register called with empty name")
+	}
+	if factory == nil {
+		panic("runtime: Register called with nil factory")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("runtime: backend %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// Lookup returns the factory registered under name, or false if none exists.
+func Lookup(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// New builds a RuntimeWithExecutor for the named backend using config, which
+// is passed through to the backend's Factory unmodified.
+//
+// This package only provides the registry; selecting a backend by name at
+// startup is the embedder's job. The one binary in this module that does
+// so is cmd/containerd-shim-sandboxrls-v1, via shimservice.New reading the
+// SANDBOXRLS_RUNTIME environment variable — there's no standalone
+// sandbox-rls CLI to wire a flag into.
+func New(name string, config interface{}) (RuntimeWithExecutor, error) {
+	factory, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("runtime: no backend registered with name %q", name)
+	}
+	return factory(config)
+}
+
+// Names returns the names of all currently registered backends.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
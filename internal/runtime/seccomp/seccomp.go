@@ -0,0 +1,208 @@
+// Package seccomp compiles a types.SeccompProfile into a classic BPF (cBPF)
+// program in the exact binary layout the kernel expects for
+// prctl(PR_SET_SECCOMP, SECCOMP_MODE_FILTER, ...) — the same format bwrap's
+// --seccomp flag loads from a file descriptor. It intentionally avoids
+// cgo/libseccomp so sandbox-rls stays a single static binary; the generated
+// programs only need to express "allow/deny by syscall number", which a
+// handful of classic BPF instructions can do directly.
+package seccomp
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ajaxzhan/sandbox-rls/pkg/types"
+)
+
+// Classic BPF opcodes (see linux/filter.h / linux/bpf_common.h). Only the
+// instruction classes this compiler emits are listed.
+const (
+	bpfLdW  = 0x00 | 0x00 | 0x20 // BPF_LD | BPF_W | BPF_ABS
+	bpfJeqK = 0x05 | 0x10 | 0x00 // BPF_JMP | BPF_JEQ | BPF_K
+	bpfRetK = 0x06 | 0x00        // BPF_RET | BPF_K
+)
+
+// Byte offsets into struct seccomp_data that BPF_LD+BPF_W+BPF_ABS reads from:
+// `nr` (syscall number) first, then `arch` (audit architecture).
+const (
+	seccompDataNrOffset   = 0
+	seccompDataArchOffset = 4
+)
+
+// auditArchX8664 is AUDIT_ARCH_X86_64 (linux/audit.h): EM_X86_64 (0x3e) with
+// __AUDIT_ARCH_64BIT and __AUDIT_ARCH_LE set. This is the only arch these
+// profiles are compiled for (syscallNumbers is amd64-specific); any other
+// value means the call came in through a different ABI — the 32-bit or x32
+// syscall table reachable via int 0x80/the x32 entry point on an amd64
+// kernel — whose numbers don't match the ones compiled into this program, so
+// it must be killed rather than let the nr check below run against the
+// wrong table.
+const auditArchX8664 = 0xc000003e
+
+// Seccomp return actions (see linux/seccomp.h SECCOMP_RET_*). ERRNO uses
+// EPERM in the low 16 bits, matching the common convention for "deny".
+const (
+	retAllow uint32 = 0x7fff0000
+	retErrno uint32 = 0x00050000 | 1 // EPERM
+	retKill  uint32 = 0x80000000
+	retTrace uint32 = 0x7ff00000
+	retLog   uint32 = 0x7ffc0000
+)
+
+// sockFilter mirrors struct sock_filter from linux/filter.h. Its memory
+// layout (not its Go field order) is what matters: the kernel and bwrap
+// read this as a flat array of 8-byte records.
+type sockFilter struct {
+	Code uint16
+	Jt   uint8
+	Jf   uint8
+	K    uint32
+}
+
+func actionToRet(action types.SeccompAction) (uint32, error) {
+	switch action {
+	case types.SeccompActionAllow:
+		return retAllow, nil
+	case types.SeccompActionErrno:
+		return retErrno, nil
+	case types.SeccompActionKill:
+		return retKill, nil
+	case types.SeccompActionTrace:
+		return retTrace, nil
+	case types.SeccompActionLog:
+		return retLog, nil
+	default:
+		return 0, fmt.Errorf("seccomp: unknown action %q", action)
+	}
+}
+
+// Compile turns profile into a serialized cBPF program. profile.Path must
+// already be resolved (see Resolve) — Compile only understands inline
+// rules.
+func Compile(profile *types.SeccompProfile) ([]byte, error) {
+	if profile.Path != "" {
+		return nil, fmt.Errorf("seccomp: Compile requires a resolved profile; call Resolve first")
+	}
+
+	defaultRet, err := actionToRet(profile.DefaultAction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the calling convention's architecture before trusting nr at all:
+	// without this, a blocked syscall is reachable by re-entering through a
+	// different ABI (e.g. the 32-bit or x32 tables on an amd64 kernel) whose
+	// numbers alias whatever this profile allows under the 64-bit table.
+	instructions := []sockFilter{
+		{Code: bpfLdW, K: seccompDataArchOffset},
+		{Code: bpfJeqK, Jt: 1, Jf: 0, K: auditArchX8664},
+		{Code: bpfRetK, K: retKill},
+	}
+
+	// Load the syscall number once; every rule below compares against it.
+	instructions = append(instructions, sockFilter{Code: bpfLdW, K: seccompDataNrOffset})
+
+	for _, rule := range profile.Syscalls {
+		if len(rule.Args) > 0 {
+			return nil, fmt.Errorf("seccomp: per-argument filtering is not yet supported (rule for %v)", rule.Names)
+		}
+
+		ret, err := actionToRet(rule.Action)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range rule.Names {
+			nr, ok := syscallNumbers[name]
+			if !ok {
+				return nil, fmt.Errorf("seccomp: unknown syscall %q for this architecture", name)
+			}
+
+			// If nr matches, fall through to the RET right after this
+			// instruction (jt=0); otherwise skip over it (jf=1) to reach
+			// the next rule's comparison.
+			instructions = append(instructions,
+				sockFilter{Code: bpfJeqK, Jt: 0, Jf: 1, K: nr},
+				sockFilter{Code: bpfRetK, K: ret},
+			)
+		}
+	}
+
+	instructions = append(instructions, sockFilter{Code: bpfRetK, K: defaultRet})
+
+	if len(instructions) > 0xffff {
+		return nil, fmt.Errorf("seccomp: compiled program has %d instructions, exceeding BPF's limit", len(instructions))
+	}
+
+	buf := make([]byte, 0, len(instructions)*8)
+	for _, instr := range instructions {
+		var raw [8]byte
+		binary.LittleEndian.PutUint16(raw[0:2], instr.Code)
+		raw[2] = instr.Jt
+		raw[3] = instr.Jf
+		binary.LittleEndian.PutUint32(raw[4:8], instr.K)
+		buf = append(buf, raw[:]...)
+	}
+
+	return buf, nil
+}
+
+// ociSeccompProfile is the subset of the OCI runtime-spec's linux.seccomp
+// JSON schema this package understands.
+type ociSeccompProfile struct {
+	DefaultAction string `json:"defaultAction"`
+	Syscalls      []struct {
+		Names  []string `json:"names"`
+		Action string   `json:"action"`
+	} `json:"syscalls"`
+}
+
+// ociActionToSeccompAction maps OCI's "SCMP_ACT_*" action names to our
+// SeccompAction values.
+var ociActionToSeccompAction = map[string]types.SeccompAction{
+	"SCMP_ACT_ALLOW": types.SeccompActionAllow,
+	"SCMP_ACT_ERRNO": types.SeccompActionErrno,
+	"SCMP_ACT_KILL":  types.SeccompActionKill,
+	"SCMP_ACT_TRACE": types.SeccompActionTrace,
+	"SCMP_ACT_LOG":   types.SeccompActionLog,
+}
+
+// Resolve returns profile unchanged unless profile.Path is set, in which
+// case it loads and parses the OCI-style JSON profile at that path and
+// returns the equivalent inline profile.
+func Resolve(profile *types.SeccompProfile) (*types.SeccompProfile, error) {
+	if profile.Path == "" {
+		return profile, nil
+	}
+
+	data, err := os.ReadFile(profile.Path)
+	if err != nil {
+		return nil, fmt.Errorf("seccomp: failed to read profile %s: %w", profile.Path, err)
+	}
+
+	var oci ociSeccompProfile
+	if err := json.Unmarshal(data, &oci); err != nil {
+		return nil, fmt.Errorf("seccomp: failed to parse profile %s: %w", profile.Path, err)
+	}
+
+	defaultAction, ok := ociActionToSeccompAction[oci.DefaultAction]
+	if !ok {
+		return nil, fmt.Errorf("seccomp: unknown defaultAction %q in %s", oci.DefaultAction, profile.Path)
+	}
+
+	resolved := &types.SeccompProfile{DefaultAction: defaultAction}
+	for _, s := range oci.Syscalls {
+		action, ok := ociActionToSeccompAction[s.Action]
+		if !ok {
+			return nil, fmt.Errorf("seccomp: unknown action %q in %s", s.Action, profile.Path)
+		}
+		resolved.Syscalls = append(resolved.Syscalls, types.SeccompSyscallRule{
+			Names:  s.Names,
+			Action: action,
+		})
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,149 @@
+package seccomp
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ajaxzhan/sandbox-rls/pkg/types"
+)
+
+// decodeInstructions reinterprets buf (as produced by Compile) back into
+// sockFilter records, so tests can assert on the compiled program's
+// structure instead of comparing raw bytes.
+func decodeInstructions(t *testing.T, buf []byte) []sockFilter {
+	t.Helper()
+	if len(buf)%8 != 0 {
+		t.Fatalf("compiled program length %d is not a multiple of 8", len(buf))
+	}
+	instructions := make([]sockFilter, 0, len(buf)/8)
+	for i := 0; i < len(buf); i += 8 {
+		raw := buf[i : i+8]
+		instructions = append(instructions, sockFilter{
+			Code: binary.LittleEndian.Uint16(raw[0:2]),
+			Jt:   raw[2],
+			Jf:   raw[3],
+			K:    binary.LittleEndian.Uint32(raw[4:8]),
+		})
+	}
+	return instructions
+}
+
+func TestCompileChecksArchBeforeSyscallNumber(t *testing.T) {
+	profile := &types.SeccompProfile{
+		DefaultAction: types.SeccompActionAllow,
+		Syscalls: []types.SeccompSyscallRule{
+			{Names: []string{"ptrace"}, Action: types.SeccompActionKill},
+		},
+	}
+
+	buf, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	instructions := decodeInstructions(t, buf)
+
+	if len(instructions) < 3 {
+		t.Fatalf("expected at least 3 instructions, got %d", len(instructions))
+	}
+
+	want := []sockFilter{
+		{Code: bpfLdW, K: seccompDataArchOffset},
+		{Code: bpfJeqK, Jt: 1, Jf: 0, K: auditArchX8664},
+		{Code: bpfRetK, K: retKill},
+	}
+	for i, w := range want {
+		if instructions[i] != w {
+			t.Fatalf("instruction %d = %+v, want %+v", i, instructions[i], w)
+		}
+	}
+
+	if instructions[3] != (sockFilter{Code: bpfLdW, K: seccompDataNrOffset}) {
+		t.Fatalf("instruction 3 = %+v, want the nr load", instructions[3])
+	}
+}
+
+func TestCompileEmitsRuleForEachSyscall(t *testing.T) {
+	profile := &types.SeccompProfile{
+		DefaultAction: types.SeccompActionErrno,
+		Syscalls: []types.SeccompSyscallRule{
+			{Names: []string{"read", "write"}, Action: types.SeccompActionAllow},
+		},
+	}
+
+	buf, err := Compile(profile)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	instructions := decodeInstructions(t, buf)
+
+	// arch check (3) + nr load (1) + 2 rules * 2 instructions each + default ret.
+	wantLen := 3 + 1 + 2*2 + 1
+	if len(instructions) != wantLen {
+		t.Fatalf("got %d instructions, want %d", len(instructions), wantLen)
+	}
+
+	last := instructions[len(instructions)-1]
+	if last != (sockFilter{Code: bpfRetK, K: retErrno}) {
+		t.Fatalf("final instruction = %+v, want default-action RET", last)
+	}
+}
+
+func TestCompileRejectsUnknownSyscall(t *testing.T) {
+	profile := &types.SeccompProfile{
+		DefaultAction: types.SeccompActionAllow,
+		Syscalls: []types.SeccompSyscallRule{
+			{Names: []string{"not_a_real_syscall"}, Action: types.SeccompActionKill},
+		},
+	}
+
+	if _, err := Compile(profile); err == nil {
+		t.Fatal("Compile did not error on an unknown syscall name")
+	}
+}
+
+func TestCompileRejectsUnresolvedProfile(t *testing.T) {
+	profile := &types.SeccompProfile{Path: "/some/profile.json"}
+
+	if _, err := Compile(profile); err == nil {
+		t.Fatal("Compile did not error on a profile with Path still set")
+	}
+}
+
+func TestResolveParsesOCIProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.json")
+	data := `{
+		"defaultAction": "SCMP_ACT_ERRNO",
+		"syscalls": [{"names": ["read", "write"], "action": "SCMP_ACT_ALLOW"}]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	resolved, err := Resolve(&types.SeccompProfile{Path: path})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	if resolved.DefaultAction != types.SeccompActionErrno {
+		t.Fatalf("DefaultAction = %q, want %q", resolved.DefaultAction, types.SeccompActionErrno)
+	}
+	if len(resolved.Syscalls) != 1 || resolved.Syscalls[0].Action != types.SeccompActionAllow {
+		t.Fatalf("Syscalls = %+v, want one ALLOW rule", resolved.Syscalls)
+	}
+}
+
+func TestResolveRejectsUnknownAction(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.json")
+	data := `{"defaultAction": "SCMP_ACT_NONSENSE"}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	if _, err := Resolve(&types.SeccompProfile{Path: path}); err == nil {
+		t.Fatal("Resolve did not error on an unknown defaultAction")
+	}
+}
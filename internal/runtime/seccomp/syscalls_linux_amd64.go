@@ -0,0 +1,66 @@
+//go:build linux && amd64
+
+package seccomp
+
+// syscallNumbers maps syscall names to their x86-64 syscall numbers. It only
+// needs to cover syscalls actually referenced by a SeccompProfile's rules
+// (built-in profiles and their hardening rules in particular), not the full
+// table.
+var syscallNumbers = map[string]uint32{
+	"read":  0,
+	"write": 1,
+	"open":  2,
+	"close": 3,
+
+	"ptrace":        101,
+	"mount":         165,
+	"umount2":       166,
+	"pivot_root":    155,
+	"quotactl":      179,
+	"reboot":        169,
+	"kexec_load":    246,
+	"init_module":   175,
+	"delete_module": 176,
+	"acct":          163,
+	"swapon":        167,
+	"swapoff":       168,
+	"sethostname":   170,
+	"setdomainname": 171,
+	"iopl":          172,
+	"ioperm":        173,
+
+	"unlink":    87,
+	"unlinkat":  263,
+	"rename":    82,
+	"renameat":  264,
+	"renameat2": 316,
+	"mkdir":     83,
+	"mkdirat":   258,
+	"rmdir":     84,
+	"chmod":     90,
+	"fchmod":    91,
+	"fchmodat":  268,
+	"chown":     92,
+	"fchown":    93,
+	"lchown":    94,
+	"fchownat":  260,
+	"truncate":  76,
+	"ftruncate": 77,
+
+	"socket":      41,
+	"connect":     42,
+	"bind":        49,
+	"listen":      50,
+	"accept":      43,
+	"accept4":     288,
+	"sendto":      44,
+	"recvfrom":    45,
+	"sendmsg":     46,
+	"recvmsg":     47,
+	"shutdown":    48,
+	"getsockname": 51,
+	"getpeername": 52,
+	"socketpair":  53,
+	"setsockopt":  54,
+	"getsockopt":  55,
+}
@@ -0,0 +1,8 @@
+//go:build !(linux && amd64)
+
+package seccomp
+
+// syscallNumbers is empty on architectures we don't have a syscall table
+// for yet; Compile will fail with a clear error for any rule referencing a
+// syscall name instead of silently producing a wrong filter.
+var syscallNumbers = map[string]uint32{}
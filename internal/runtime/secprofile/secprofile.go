@@ -0,0 +1,106 @@
+// Package secprofile ships a small set of built-in security profiles so
+// callers can reference common capability/seccomp combinations by name
+// (e.g. in SandboxConfig.SecurityProfile.Name) instead of spelling them out
+// every time.
+package secprofile
+
+import "github.com/ajaxzhan/sandbox-rls/pkg/types"
+
+// hardeningSyscalls denies syscalls that let a process escape or interfere
+// with the host: tracing other processes, mounting filesystems, loading
+// kernel modules, and changing host-wide identity/power state. Every
+// built-in profile includes these; more restrictive profiles add to them.
+var hardeningSyscalls = []types.SeccompSyscallRule{
+	{
+		Names: []string{
+			"ptrace", "mount", "umount2", "pivot_root", "quotactl",
+			"reboot", "kexec_load", "init_module", "delete_module",
+			"acct", "swapon", "swapoff", "sethostname", "setdomainname",
+			"iopl", "ioperm",
+		},
+		Action: types.SeccompActionErrno,
+	},
+}
+
+// writeDenySyscalls additionally denies syscalls that mutate the
+// filesystem, for profiles that should only be able to read it.
+var writeDenySyscalls = []types.SeccompSyscallRule{
+	{
+		Names: []string{
+			"unlink", "unlinkat", "rename", "renameat", "renameat2",
+			"mkdir", "mkdirat", "rmdir", "chmod", "fchmod", "fchmodat",
+			"chown", "fchown", "lchown", "fchownat", "truncate", "ftruncate",
+		},
+		Action: types.SeccompActionErrno,
+	},
+}
+
+// networkDenySyscalls additionally denies syscalls that create or use
+// sockets, for profiles that should have no network access at all.
+var networkDenySyscalls = []types.SeccompSyscallRule{
+	{
+		Names: []string{
+			"socket", "connect", "bind", "listen", "accept", "accept4",
+			"sendto", "recvfrom", "sendmsg", "recvmsg", "shutdown",
+			"getsockname", "getpeername", "socketpair", "setsockopt", "getsockopt",
+		},
+		Action: types.SeccompActionErrno,
+	},
+}
+
+var profiles = map[string]types.SecurityProfile{
+	// default is a general-purpose profile: drop all capabilities except
+	// the handful needed to run ordinary (non-root) build/test commands,
+	// and deny syscalls that would let a process touch the host rather
+	// than its own sandbox.
+	"default": {
+		Name:    "default",
+		CapDrop: []string{"ALL"},
+		CapAdd:  []string{"CHOWN", "DAC_OVERRIDE", "FOWNER", "SETGID", "SETUID", "KILL"},
+		Seccomp: &types.SeccompProfile{
+			DefaultAction: types.SeccompActionAllow,
+			Syscalls:      hardeningSyscalls,
+		},
+	},
+
+	// read-only additionally drops the capabilities and syscalls needed to
+	// modify the filesystem, for sandboxes that should only inspect a
+	// codebase.
+	"read-only": {
+		Name:    "read-only",
+		CapDrop: []string{"ALL"},
+		CapAdd:  []string{"SETGID", "SETUID"},
+		Seccomp: &types.SeccompProfile{
+			DefaultAction: types.SeccompActionAllow,
+			Syscalls:      append(append([]types.SeccompSyscallRule{}, hardeningSyscalls...), writeDenySyscalls...),
+		},
+	},
+
+	// network-none is the default profile plus a full syscall-level deny on
+	// socket use, for sandboxes run with EnableNetworking=false that should
+	// not even be able to attempt a connection.
+	"network-none": {
+		Name:    "network-none",
+		CapDrop: []string{"ALL"},
+		CapAdd:  []string{"CHOWN", "DAC_OVERRIDE", "FOWNER", "SETGID", "SETUID", "KILL"},
+		Seccomp: &types.SeccompProfile{
+			DefaultAction: types.SeccompActionAllow,
+			Syscalls:      append(append([]types.SeccompSyscallRule{}, hardeningSyscalls...), networkDenySyscalls...),
+		},
+	},
+}
+
+// Get returns the built-in profile registered under name.
+func Get(name string) (types.SecurityProfile, bool) {
+	profile, ok := profiles[name]
+	return profile, ok
+}
+
+// Names returns the names of all built-in profiles.
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	return names
+}
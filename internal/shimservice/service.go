@@ -0,0 +1,796 @@
+// Package shimservice implements containerd's Task Service v2 (shim v2) by
+// delegating sandbox and process lifecycle to a runtime.RuntimeWithExecutor
+// backend. This lets containerd (and anything that speaks its task API,
+// such as an agent orchestrator) drive sandbox-rls sandboxes the same way
+// it drives any other container runtime, instead of through a bespoke
+// client.
+//
+// A shim process is created per task by containerd and owns exactly one
+// sandbox, identified by the task ID. The OCI bundle's config.json supplies
+// the sandbox's rootfs and entrypoint; the init process and any additional
+// Exec calls are each run to completion via the backend's synchronous Exec
+// method, with their captured stdio flushed to the task's stdio FIFOs
+// afterward. Real-time stdio streaming into those FIFOs (so `ctr t attach`
+// shows output live) is follow-up work, since RuntimeWithExecutor.ExecStream
+// doesn't yet surface a uniform exit code across backends the way Exec
+// does.
+package shimservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	eventstypes "github.com/containerd/containerd/api/events"
+	taskAPI "github.com/containerd/containerd/api/runtime/task/v2"
+	task "github.com/containerd/containerd/api/types/task"
+	"github.com/containerd/containerd/events"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/protobuf"
+	ptypes "github.com/containerd/containerd/protobuf/types"
+	"github.com/containerd/containerd/runtime/v2/shim"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/fifo"
+	typeurl "github.com/containerd/typeurl/v2"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+
+	rt "github.com/ajaxzhan/sandbox-rls/internal/runtime"
+	sbtypes "github.com/ajaxzhan/sandbox-rls/pkg/types"
+)
+
+// backendEnvVar names the environment variable used to select which
+// registered runtime.RuntimeWithExecutor backend this shim drives. It is
+// read once, in New, since a shim process is scoped to a single task.
+const backendEnvVar = "SANDBOXRLS_RUNTIME"
+
+// defaultBackend is the backend used when backendEnvVar is unset.
+const defaultBackend = "bwrap"
+
+// execState is an in-flight or completed init process or exec, keyed in
+// service.execs by its exec ID ("" for the task's init process).
+type execState struct {
+	command string
+	env     map[string]string
+
+	terminal bool
+	stdin    string
+	stdout   string
+	stderr   string
+
+	started bool
+	cancel  context.CancelFunc
+
+	exited   bool
+	exitCode uint32
+	exitedAt time.Time
+	waitCh   chan struct{}
+}
+
+// service implements shim.Shim (shimapi.TaskService plus Cleanup/StartShim)
+// for a single task, identified by id.
+type service struct {
+	mu sync.Mutex
+
+	id        string
+	bundle    string
+	publisher shim.Publisher
+	shutdown  func()
+
+	runtime   rt.RuntimeWithExecutor
+	createdAt time.Time
+	execs     map[string]*execState
+}
+
+// New returns a new shim service for task id. It resolves the runtime
+// backend from backendEnvVar but does not create a sandbox yet — that
+// happens on the first Create call.
+func New(ctx context.Context, id string, publisher shim.Publisher, shutdownFn func()) (shim.Shim, error) {
+	backend := os.Getenv(backendEnvVar)
+	if backend == "" {
+		backend = defaultBackend
+	}
+
+	backendRuntime, err := rt.New(backend, nil)
+	if err != nil {
+		return nil, fmt.Errorf("shimservice: resolving runtime backend %q: %w", backend, err)
+	}
+
+	return &service{
+		id:        id,
+		publisher: publisher,
+		shutdown:  shutdownFn,
+		runtime:   backendRuntime,
+		execs:     make(map[string]*execState),
+	}, nil
+}
+
+// newShimCommand builds the self re-exec used to daemonize this shim,
+// mirroring the pattern used by containerd's own runc shim.
+func newShimCommand(ctx context.Context, id string, opts shim.StartOpts) (*exec.Cmd, error) {
+	ns, err := namespaces.NamespaceRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(self, "-namespace", ns, "-id", id, "-address", opts.Address)
+	cmd.Dir = cwd
+	cmd.Env = append(os.Environ(), "GOMAXPROCS=2")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd, nil
+}
+
+// StartShim daemonizes a new shim instance for the task and returns the
+// ttrpc socket address containerd should connect to.
+func (s *service) StartShim(ctx context.Context, opts shim.StartOpts) (_ string, retErr error) {
+	cmd, err := newShimCommand(ctx, opts.ID, opts)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := shim.SocketAddress(ctx, opts.Address, opts.ID)
+	if err != nil {
+		return "", err
+	}
+
+	socket, err := shim.NewSocket(address)
+	if err != nil {
+		if !shim.SocketEaddrinuse(err) {
+			return "", err
+		}
+		if err := shim.RemoveSocket(address); err != nil {
+			return "", fmt.Errorf("shimservice: removing stale socket: %w", err)
+		}
+		if socket, err = shim.NewSocket(address); err != nil {
+			return "", err
+		}
+	}
+	defer func() {
+		if retErr != nil {
+			socket.Close()
+			_ = shim.RemoveSocket(address)
+		}
+	}()
+
+	if err := shim.WriteAddress("address", address); err != nil {
+		return "", err
+	}
+
+	f, err := socket.File()
+	if err != nil {
+		return "", err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+
+	if err := cmd.Start(); err != nil {
+		f.Close()
+		return "", err
+	}
+	defer func() {
+		if retErr != nil {
+			cmd.Process.Kill()
+		}
+	}()
+	go cmd.Wait()
+
+	if err := shim.WritePidFile("shim.pid", cmd.Process.Pid); err != nil {
+		return "", err
+	}
+	return address, nil
+}
+
+// Cleanup tears down whatever this shim left behind when containerd asks it
+// to clean up without an active ttrpc connection (e.g. after a crash).
+func (s *service) Cleanup(ctx context.Context) (*taskAPI.DeleteResponse, error) {
+	if address, err := shim.ReadAddress("address"); err == nil {
+		_ = shim.RemoveSocket(address)
+	}
+
+	s.mu.Lock()
+	id := s.id
+	s.mu.Unlock()
+
+	if id != "" {
+		_ = s.runtime.Destroy(ctx, id)
+	}
+	return &taskAPI.DeleteResponse{ExitedAt: protobuf.ToTimestamp(time.Now())}, nil
+}
+
+// publish forwards event to containerd, logging (rather than failing the
+// calling RPC) if delivery fails — the task operation itself already
+// succeeded by the time events are published.
+func (s *service) publish(ctx context.Context, topic string, event events.Event) {
+	if err := s.publisher.Publish(ctx, topic, event); err != nil {
+		fmt.Fprintf(os.Stderr, "shimservice: failed to publish %s: %v\n", topic, err)
+	}
+}
+
+// readBundleSpec loads the OCI runtime spec written by containerd into the
+// bundle directory.
+func readBundleSpec(bundle string) (*specs.Spec, error) {
+	data, err := os.ReadFile(filepath.Join(bundle, "config.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading config.json: %w", err)
+	}
+	var spec specs.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parsing config.json: %w", err)
+	}
+	return &spec, nil
+}
+
+// rootfsPath resolves an OCI spec's root.path relative to bundle.
+func rootfsPath(bundle string, spec *specs.Spec) string {
+	path := "rootfs"
+	if spec.Root != nil && spec.Root.Path != "" {
+		path = spec.Root.Path
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(bundle, path)
+}
+
+// envMap converts an OCI process's "KEY=VALUE" environment slice into the
+// map shape SandboxConfig and ExecRequest use.
+func envMap(env []string) map[string]string {
+	out := make(map[string]string, len(env))
+	for _, kv := range env {
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			out[kv[:i]] = kv[i+1:]
+		}
+	}
+	return out
+}
+
+// shellJoin renders args as a single POSIX shell command string, since
+// every RuntimeWithExecutor backend runs ExecRequest.Command via `sh -c`.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Create creates this task's sandbox from its OCI bundle. The bundle's
+// process becomes the task's init exec (exec ID ""), started later by
+// Start.
+func (s *service) Create(ctx context.Context, r *taskAPI.CreateTaskRequest) (*taskAPI.CreateTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.execs[""]; ok {
+		return nil, fmt.Errorf("shimservice: task %s already created: %w", r.ID, errdefs.ErrAlreadyExists)
+	}
+
+	spec, err := readBundleSpec(r.Bundle)
+	if err != nil {
+		return nil, fmt.Errorf("shimservice: %w", err)
+	}
+
+	root := rootfsPath(r.Bundle, spec)
+	cfg := &rt.SandboxConfig{
+		ID:           r.ID,
+		CodebasePath: root,
+		MountPoint:   root,
+	}
+
+	init := &execState{waitCh: make(chan struct{}), terminal: r.Terminal, stdin: r.Stdin, stdout: r.Stdout, stderr: r.Stderr}
+	if spec.Process != nil {
+		init.command = shellJoin(spec.Process.Args)
+		init.env = envMap(spec.Process.Env)
+		cfg.Args = spec.Process.Args
+		cfg.Env = init.env
+	}
+
+	if _, err := s.runtime.Create(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("shimservice: creating sandbox: %w", err)
+	}
+
+	s.id = r.ID
+	s.bundle = r.Bundle
+	s.createdAt = time.Now()
+	s.execs[""] = init
+
+	pid := s.pidLocked(ctx)
+
+	s.publish(ctx, "/tasks/create", &eventstypes.TaskCreate{
+		ContainerID: r.ID,
+		Bundle:      r.Bundle,
+		IO: &eventstypes.TaskIO{
+			Stdin:    r.Stdin,
+			Stdout:   r.Stdout,
+			Stderr:   r.Stderr,
+			Terminal: r.Terminal,
+		},
+		Pid: pid,
+	})
+
+	return &taskAPI.CreateTaskResponse{Pid: pid}, nil
+}
+
+// pidLocked returns the sandbox's OS pid if the backend exposes one
+// (runtime.PidProvider), or a synthetic non-zero pid otherwise — containerd
+// only cares that a task's pid is nonzero while it's running. s.mu must be
+// held by the caller.
+func (s *service) pidLocked(ctx context.Context) uint32 {
+	if provider, ok := s.runtime.(rt.PidProvider); ok {
+		if pid, err := provider.Pid(ctx, s.id); err == nil {
+			return uint32(pid)
+		}
+	}
+	return 1
+}
+
+// Start launches the init process (execID "") or an additional exec
+// previously registered via Exec.
+func (s *service) Start(ctx context.Context, r *taskAPI.StartRequest) (*taskAPI.StartResponse, error) {
+	s.mu.Lock()
+	entry, ok := s.execs[r.ExecID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("shimservice: unknown exec %q: %w", r.ExecID, errdefs.ErrNotFound)
+	}
+	if entry.started {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("shimservice: exec %q already started: %w", r.ExecID, errdefs.ErrFailedPrecondition)
+	}
+	entry.started = true
+	id := s.id
+	s.mu.Unlock()
+
+	if r.ExecID == "" {
+		if err := s.runtime.Start(ctx, id); err != nil {
+			return nil, fmt.Errorf("shimservice: starting sandbox: %w", err)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	entry.cancel = cancel
+	pid := s.pidLocked(ctx)
+	s.mu.Unlock()
+
+	go s.runExec(runCtx, id, r.ExecID, entry)
+
+	if r.ExecID == "" {
+		s.publish(ctx, "/tasks/start", &eventstypes.TaskStart{ContainerID: id, Pid: pid})
+	} else {
+		s.publish(ctx, "/tasks/exec-started", &eventstypes.TaskExecStarted{ContainerID: id, ExecID: r.ExecID, Pid: pid})
+	}
+
+	return &taskAPI.StartResponse{Pid: pid}, nil
+}
+
+// runExec drives entry to completion. A terminal exec (entry.terminal) is
+// streamed live through a pty via runInteractiveExec; otherwise it reads
+// stdin (if any) up front, runs the command synchronously via the
+// backend's Exec, and flushes the captured output to the task's stdio
+// FIFOs afterward. Either way, the exit is recorded so
+// Wait/Delete/State can observe it.
+func (s *service) runExec(ctx context.Context, sandboxID, execID string, entry *execState) {
+	if entry.terminal {
+		s.runInteractiveExec(ctx, sandboxID, execID, entry)
+		return
+	}
+
+	req := &sbtypes.ExecRequest{
+		Command: entry.command,
+		Env:     entry.env,
+	}
+	if entry.stdin != "" {
+		req.Stdin = readStdinFIFO(ctx, entry.stdin)
+	}
+
+	result, err := s.runtime.Exec(ctx, sandboxID, req)
+
+	exitCode := uint32(1)
+	var stdout, stderr string
+	if err == nil {
+		exitCode = uint32(result.ExitCode)
+		stdout = result.Stdout
+		stderr = result.Stderr
+	}
+
+	writeStdioFIFO(entry.stdout, stdout)
+	writeStdioFIFO(entry.stderr, stderr)
+
+	s.recordExit(sandboxID, execID, entry, exitCode)
+}
+
+// runInteractiveExec drives a terminal exec via ExecInteractive, streaming
+// entry's stdin/stdout FIFOs through a pty for the life of the process,
+// rather than the one-shot read/run/write sequence the non-terminal path
+// uses. It requires entry.runtime.ExecInteractive, which every
+// RuntimeWithExecutor backend implements.
+func (s *service) runInteractiveExec(ctx context.Context, sandboxID, execID string, entry *execState) {
+	exitCode := uint32(1)
+
+	tty, err := openFifoTTY(ctx, entry.stdin, entry.stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "shimservice: opening terminal fifos for exec %q: %v\n", execID, err)
+		s.recordExit(sandboxID, execID, entry, exitCode)
+		return
+	}
+	defer tty.Close()
+
+	req := &sbtypes.ExecRequest{
+		Command: entry.command,
+		Env:     entry.env,
+		TTY:     true,
+	}
+
+	if err := s.runtime.ExecInteractive(ctx, sandboxID, req, tty); err == nil {
+		exitCode = 0
+	}
+
+	s.recordExit(sandboxID, execID, entry, exitCode)
+}
+
+// recordExit marks entry as exited, unblocking Wait, and publishes the
+// task's /tasks/exit event.
+func (s *service) recordExit(sandboxID, execID string, entry *execState, exitCode uint32) {
+	s.mu.Lock()
+	entry.exited = true
+	entry.exitCode = exitCode
+	entry.exitedAt = time.Now()
+	close(entry.waitCh)
+	s.mu.Unlock()
+
+	s.publish(context.Background(), "/tasks/exit", &eventstypes.TaskExit{
+		ContainerID: sandboxID,
+		ID:          execID,
+		Pid:         1,
+		ExitStatus:  exitCode,
+		ExitedAt:    protobuf.ToTimestamp(entry.exitedAt),
+	})
+}
+
+// readStdinFIFO drains whatever is already buffered on a task's stdin
+// FIFO, since ExecRequest.Stdin is a one-shot string rather than a live
+// stream: it does not wait for a writer that never shows up.
+func readStdinFIFO(ctx context.Context, path string) string {
+	f, err := fifo.OpenFifo(ctx, path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var buf []byte
+	chunk := make([]byte, 4096)
+	for {
+		n, err := f.Read(chunk)
+		if n > 0 {
+			buf = append(buf, chunk[:n]...)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return string(buf)
+}
+
+// writeStdioFIFO best-effort flushes data to a task's stdout/stderr FIFO.
+func writeStdioFIFO(path, data string) {
+	if path == "" || data == "" {
+		return
+	}
+	f, err := fifo.OpenFifo(context.Background(), path, syscall.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write([]byte(data))
+}
+
+// fifoTTY adapts a terminal exec's stdin/stdout FIFOs into a
+// runtime.TTYStream, so ExecInteractive can drive them the same way it
+// drives a real pty. Unlike readStdinFIFO/writeStdioFIFO, which drain or
+// write a non-terminal exec's stdio once, both FIFOs are opened blocking
+// and streamed continuously for the life of the exec.
+type fifoTTY struct {
+	stdin  io.ReadCloser
+	stdout io.WriteCloser
+}
+
+// openFifoTTY opens a terminal exec's stdin/stdout FIFOs. Both opens block
+// until containerd's client has the other end open, same as a normal
+// task's stdio FIFOs.
+func openFifoTTY(ctx context.Context, stdinPath, stdoutPath string) (*fifoTTY, error) {
+	stdin, err := fifo.OpenFifo(ctx, stdinPath, syscall.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening stdin fifo: %w", err)
+	}
+	stdout, err := fifo.OpenFifo(ctx, stdoutPath, syscall.O_WRONLY, 0)
+	if err != nil {
+		stdin.Close()
+		return nil, fmt.Errorf("opening stdout fifo: %w", err)
+	}
+	return &fifoTTY{stdin: stdin, stdout: stdout}, nil
+}
+
+func (t *fifoTTY) Read(p []byte) (int, error)  { return t.stdin.Read(p) }
+func (t *fifoTTY) Write(p []byte) (int, error) { return t.stdout.Write(p) }
+
+// Resize is a no-op: this shim process has no controlling terminal of its
+// own to forward SIGWINCH from, so nothing ever calls it. Live resizing
+// for a terminal exec goes through ResizePty instead, which reaches the
+// runtime directly via the ExecResizer capability.
+func (t *fifoTTY) Resize(cols, rows uint16) error { return nil }
+
+// Close closes both FIFOs, making a blocked Read return so ExecInteractive's
+// input-forwarding goroutine can exit (see runtime.TTYStream).
+func (t *fifoTTY) Close() error {
+	stdinErr := t.stdin.Close()
+	stdoutErr := t.stdout.Close()
+	if stdinErr != nil {
+		return stdinErr
+	}
+	return stdoutErr
+}
+
+// Delete removes an exec's (or the task's) record, per the task API
+// contract that Delete is only valid after the process has exited.
+func (s *service) Delete(ctx context.Context, r *taskAPI.DeleteRequest) (*taskAPI.DeleteResponse, error) {
+	s.mu.Lock()
+	entry, ok := s.execs[r.ExecID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("shimservice: unknown exec %q: %w", r.ExecID, errdefs.ErrNotFound)
+	}
+	if !entry.exited {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("shimservice: exec %q has not exited: %w", r.ExecID, errdefs.ErrFailedPrecondition)
+	}
+	exitCode, exitedAt := entry.exitCode, entry.exitedAt
+	delete(s.execs, r.ExecID)
+	id := s.id
+	s.mu.Unlock()
+
+	if r.ExecID == "" {
+		if err := s.runtime.Destroy(ctx, id); err != nil && err != sbtypes.ErrSandboxNotFound {
+			return nil, fmt.Errorf("shimservice: destroying sandbox: %w", err)
+		}
+		s.publish(ctx, "/tasks/delete", &eventstypes.TaskDelete{
+			ContainerID: id,
+			Pid:         1,
+			ExitStatus:  exitCode,
+			ExitedAt:    protobuf.ToTimestamp(exitedAt),
+		})
+	}
+
+	return &taskAPI.DeleteResponse{
+		Pid:        1,
+		ExitStatus: exitCode,
+		ExitedAt:   protobuf.ToTimestamp(exitedAt),
+	}, nil
+}
+
+// Wait blocks until the given exec (or the task's init process) exits.
+func (s *service) Wait(ctx context.Context, r *taskAPI.WaitRequest) (*taskAPI.WaitResponse, error) {
+	s.mu.Lock()
+	entry, ok := s.execs[r.ExecID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("shimservice: unknown exec %q: %w", r.ExecID, errdefs.ErrNotFound)
+	}
+
+	select {
+	case <-entry.waitCh:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	exitCode, exitedAt := entry.exitCode, entry.exitedAt
+	s.mu.Unlock()
+
+	return &taskAPI.WaitResponse{
+		ExitStatus: exitCode,
+		ExitedAt:   protobuf.ToTimestamp(exitedAt),
+	}, nil
+}
+
+// State reports the current status of the task's init process or an exec.
+func (s *service) State(ctx context.Context, r *taskAPI.StateRequest) (*taskAPI.StateResponse, error) {
+	s.mu.Lock()
+	entry, ok := s.execs[r.ExecID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("shimservice: unknown exec %q: %w", r.ExecID, errdefs.ErrNotFound)
+	}
+
+	status := task.Status_CREATED
+	switch {
+	case entry.exited:
+		status = task.Status_STOPPED
+	case entry.started:
+		status = task.Status_RUNNING
+	}
+
+	resp := &taskAPI.StateResponse{
+		ID:         s.id,
+		Bundle:     s.bundle,
+		Pid:        s.pidLocked(ctx),
+		Status:     status,
+		Stdin:      entry.stdin,
+		Stdout:     entry.stdout,
+		Stderr:     entry.stderr,
+		Terminal:   entry.terminal,
+		ExecID:     r.ExecID,
+		ExitStatus: entry.exitCode,
+		ExitedAt:   protobuf.ToTimestamp(entry.exitedAt),
+	}
+	s.mu.Unlock()
+	return resp, nil
+}
+
+// Pids reports the pids associated with the task. Since execs here don't
+// map onto distinct OS processes exposed by every backend, it reports the
+// sandbox's single pid (if the backend provides one).
+func (s *service) Pids(ctx context.Context, r *taskAPI.PidsRequest) (*taskAPI.PidsResponse, error) {
+	s.mu.Lock()
+	pid := s.pidLocked(ctx)
+	s.mu.Unlock()
+
+	return &taskAPI.PidsResponse{
+		Processes: []*task.ProcessInfo{{Pid: pid}},
+	}, nil
+}
+
+// Kill stops the task's init process (by destroying its sandbox) or
+// cancels a running exec. Only termination, not a specific signal's
+// semantics, is honored — the sandbox-rls runtime model doesn't expose
+// per-process signal delivery uniformly across backends.
+func (s *service) Kill(ctx context.Context, r *taskAPI.KillRequest) (*ptypes.Empty, error) {
+	s.mu.Lock()
+	entry, ok := s.execs[r.ExecID]
+	id := s.id
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("shimservice: unknown exec %q: %w", r.ExecID, errdefs.ErrNotFound)
+	}
+
+	if r.ExecID == "" {
+		if err := s.runtime.Stop(ctx, id); err != nil {
+			return nil, fmt.Errorf("shimservice: stopping sandbox: %w", err)
+		}
+		return &ptypes.Empty{}, nil
+	}
+
+	s.mu.Lock()
+	cancel := entry.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return &ptypes.Empty{}, nil
+}
+
+// Exec registers an additional process to run inside the task's sandbox,
+// to be launched by a subsequent Start call with the same ExecID.
+func (s *service) Exec(ctx context.Context, r *taskAPI.ExecProcessRequest) (*ptypes.Empty, error) {
+	var spec specs.Process
+	if r.Spec != nil {
+		if err := typeurl.UnmarshalTo(r.Spec, &spec); err != nil {
+			return nil, fmt.Errorf("shimservice: unmarshaling exec spec: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.execs[r.ExecID]; ok {
+		return nil, fmt.Errorf("shimservice: exec %q already exists: %w", r.ExecID, errdefs.ErrAlreadyExists)
+	}
+
+	s.execs[r.ExecID] = &execState{
+		command:  shellJoin(spec.Args),
+		env:      envMap(spec.Env),
+		terminal: r.Terminal,
+		stdin:    r.Stdin,
+		stdout:   r.Stdout,
+		stderr:   r.Stderr,
+		waitCh:   make(chan struct{}),
+	}
+
+	s.publish(ctx, "/tasks/exec-added", &eventstypes.TaskExecAdded{ContainerID: s.id, ExecID: r.ExecID})
+	return &ptypes.Empty{}, nil
+}
+
+// ResizePty resizes the pty of a running terminal exec, if the backend
+// supports reaching one (rt.ExecResizer) — sandbox-rls's runtime
+// abstraction otherwise has no cross-exec-ID notion of a pty, so this
+// resizes whichever interactive exec is in flight on the sandbox.
+func (s *service) ResizePty(ctx context.Context, r *taskAPI.ResizePtyRequest) (*ptypes.Empty, error) {
+	resizer, ok := s.runtime.(rt.ExecResizer)
+	if !ok {
+		return nil, errdefs.ErrNotImplemented
+	}
+
+	s.mu.Lock()
+	id := s.id
+	s.mu.Unlock()
+
+	if err := resizer.ResizeExec(ctx, id, uint16(r.Width), uint16(r.Height)); err != nil {
+		return nil, fmt.Errorf("shimservice: resizing exec %q: %w", r.ExecID, err)
+	}
+	return &ptypes.Empty{}, nil
+}
+
+// CloseIO is a no-op: stdin is read once, up front, rather than kept open
+// for the life of the process (see runExec).
+func (s *service) CloseIO(ctx context.Context, r *taskAPI.CloseIORequest) (*ptypes.Empty, error) {
+	return &ptypes.Empty{}, nil
+}
+
+// Pause is not supported: the runtime abstraction has no pause primitive.
+func (s *service) Pause(ctx context.Context, r *taskAPI.PauseRequest) (*ptypes.Empty, error) {
+	return nil, errdefs.ErrNotImplemented
+}
+
+// Resume is not supported, for the same reason as Pause.
+func (s *service) Resume(ctx context.Context, r *taskAPI.ResumeRequest) (*ptypes.Empty, error) {
+	return nil, errdefs.ErrNotImplemented
+}
+
+// Checkpoint is not supported: no backend implements checkpoint/restore.
+func (s *service) Checkpoint(ctx context.Context, r *taskAPI.CheckpointTaskRequest) (*ptypes.Empty, error) {
+	return nil, errdefs.ErrNotImplemented
+}
+
+// Update is not supported: resource limits are fixed at sandbox Create
+// time (see runtime.Resources) and cannot be changed on a running sandbox.
+func (s *service) Update(ctx context.Context, r *taskAPI.UpdateTaskRequest) (*ptypes.Empty, error) {
+	return nil, errdefs.ErrNotImplemented
+}
+
+// Stats returns the sandbox's resource usage, translated from
+// types.SandboxStats into the cgroup-shaped metrics containerd expects.
+// Since there's no cross-runtime stats proto in this module's
+// dependencies, callers that need structured stats should call the
+// backend directly; Stats reports ErrNotImplemented here.
+func (s *service) Stats(ctx context.Context, r *taskAPI.StatsRequest) (*taskAPI.StatsResponse, error) {
+	return nil, errdefs.ErrNotImplemented
+}
+
+// Connect reports this shim's pid and the task's pid.
+func (s *service) Connect(ctx context.Context, r *taskAPI.ConnectRequest) (*taskAPI.ConnectResponse, error) {
+	s.mu.Lock()
+	pid := s.pidLocked(ctx)
+	s.mu.Unlock()
+
+	return &taskAPI.ConnectResponse{
+		ShimPid: uint32(os.Getpid()),
+		TaskPid: pid,
+	}, nil
+}
+
+// Shutdown destroys the sandbox and terminates the shim process.
+func (s *service) Shutdown(ctx context.Context, r *taskAPI.ShutdownRequest) (*ptypes.Empty, error) {
+	s.mu.Lock()
+	id := s.id
+	s.mu.Unlock()
+
+	if id != "" {
+		_ = s.runtime.Destroy(ctx, id)
+	}
+	s.shutdown()
+	return &ptypes.Empty{}, nil
+}
@@ -0,0 +1,96 @@
+package shimservice
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func writeBundleSpec(t *testing.T, bundle string, spec *specs.Spec) {
+	t.Helper()
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("marshaling spec: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "config.json"), data, 0o644); err != nil {
+		t.Fatalf("writing config.json: %v", err)
+	}
+}
+
+func TestReadBundleSpecParsesConfigJSON(t *testing.T) {
+	bundle := t.TempDir()
+	writeBundleSpec(t, bundle, &specs.Spec{
+		Root:    &specs.Root{Path: "rootfs"},
+		Process: &specs.Process{Args: []string{"/bin/sh"}, Cwd: "/"},
+	})
+
+	spec, err := readBundleSpec(bundle)
+	if err != nil {
+		t.Fatalf("readBundleSpec: %v", err)
+	}
+	if spec.Root.Path != "rootfs" {
+		t.Errorf("Root.Path = %q, want \"rootfs\"", spec.Root.Path)
+	}
+	if len(spec.Process.Args) != 1 || spec.Process.Args[0] != "/bin/sh" {
+		t.Errorf("Process.Args = %v, want [\"/bin/sh\"]", spec.Process.Args)
+	}
+}
+
+func TestReadBundleSpecMissingConfigErrors(t *testing.T) {
+	if _, err := readBundleSpec(t.TempDir()); err == nil {
+		t.Fatal("expected an error for a bundle with no config.json, got nil")
+	}
+}
+
+func TestRootfsPathJoinsRelativeRootUnderBundle(t *testing.T) {
+	bundle := "/bundles/task-1"
+	spec := &specs.Spec{Root: &specs.Root{Path: "rootfs"}}
+
+	if got, want := rootfsPath(bundle, spec), filepath.Join(bundle, "rootfs"); got != want {
+		t.Errorf("rootfsPath = %q, want %q", got, want)
+	}
+}
+
+func TestRootfsPathLeavesAbsoluteRootAlone(t *testing.T) {
+	spec := &specs.Spec{Root: &specs.Root{Path: "/var/lib/rootfs"}}
+
+	if got, want := rootfsPath("/bundles/task-1", spec), "/var/lib/rootfs"; got != want {
+		t.Errorf("rootfsPath = %q, want %q", got, want)
+	}
+}
+
+func TestRootfsPathDefaultsWhenRootUnset(t *testing.T) {
+	if got, want := rootfsPath("/bundles/task-1", &specs.Spec{}), filepath.Join("/bundles/task-1", "rootfs"); got != want {
+		t.Errorf("rootfsPath = %q, want %q", got, want)
+	}
+}
+
+func TestEnvMapSplitsKeyValuePairs(t *testing.T) {
+	got := envMap([]string{"FOO=bar", "EMPTY=", "NOEQUALS"})
+	want := map[string]string{"FOO": "bar", "EMPTY": ""}
+
+	if len(got) != len(want) {
+		t.Fatalf("envMap = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("envMap[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["NOEQUALS"]; ok {
+		t.Errorf("envMap included %q, which has no '='", "NOEQUALS")
+	}
+}
+
+func TestShellJoinQuotesArgsForShC(t *testing.T) {
+	got := shellJoin([]string{"echo", "it's fine"})
+	want := `'echo' 'it'\''s fine'`
+
+	if got != want {
+		t.Errorf("shellJoin = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Package types defines the core data types shared across sandbox-rls
+// runtimes: sandbox metadata, exec requests/results, and sentinel errors.
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// Status represents the lifecycle state of a sandbox.
+type Status string
+
+const (
+	// StatusPending indicates the sandbox has been created but not started.
+	StatusPending Status = "pending"
+	// StatusRunning indicates the sandbox is active and can accept exec requests.
+	StatusRunning Status = "running"
+	// StatusStopped indicates the sandbox was stopped but not yet destroyed.
+	StatusStopped Status = "stopped"
+)
+
+// Sentinel errors returned by runtime implementations.
+var (
+	ErrSandboxNotFound = errors.New("sandbox not found")
+	ErrAlreadyRunning  = errors.New("sandbox is already running")
+	ErrNotRunning      = errors.New("sandbox is not running")
+	ErrTimeout         = errors.New("operation timed out")
+)
+
+// Sandbox describes the current state of a sandboxed environment.
+type Sandbox struct {
+	ID          string
+	CodebaseID  string
+	Permissions []string
+	Status      Status
+	Labels      map[string]string
+	MountPoint  string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	StoppedAt   *time.Time
+}
+
+// ExecRequest describes a command to run inside a sandbox.
+type ExecRequest struct {
+	Command string
+	Env     map[string]string
+	WorkDir string
+	Stdin   string
+	Timeout time.Duration
+
+	// TTY requests that the command run with a pseudo-terminal attached
+	// instead of plain pipes, via RuntimeWithExecutor.ExecInteractive.
+	// Rows/Cols give the pty's initial size; zero means a runtime-chosen
+	// default (typically 80x24).
+	TTY  bool
+	Rows uint16
+	Cols uint16
+}
+
+// ExecResult holds the outcome of a command executed inside a sandbox.
+type ExecResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// SeccompAction is the action a seccomp rule takes when a syscall matches.
+type SeccompAction string
+
+const (
+	SeccompActionAllow SeccompAction = "ALLOW"
+	SeccompActionErrno SeccompAction = "ERRNO"
+	SeccompActionKill  SeccompAction = "KILL"
+	SeccompActionTrace SeccompAction = "TRACE"
+	SeccompActionLog   SeccompAction = "LOG"
+)
+
+// SeccompArgRule narrows a SeccompSyscallRule to invocations where a
+// specific syscall argument matches a value, e.g. the flags argument of
+// open(2).
+type SeccompArgRule struct {
+	Index    uint
+	Value    uint64
+	ValueTwo uint64
+	Op       string // one of "EQ", "NE", "LT", "LE", "GT", "GE", "MASKED_EQ"
+}
+
+// SeccompSyscallRule applies Action to every syscall in Names, optionally
+// narrowed by Args.
+type SeccompSyscallRule struct {
+	Names  []string
+	Action SeccompAction
+	Args   []SeccompArgRule
+}
+
+// SeccompProfile describes a seccomp filter, either as a path to an
+// OCI-style JSON profile on disk or as an inline syscall rule list. If Path
+// is set, it takes precedence and Syscalls/DefaultAction are ignored.
+type SeccompProfile struct {
+	Path          string
+	DefaultAction SeccompAction
+	Syscalls      []SeccompSyscallRule
+}
+
+// SecurityProfile describes the capability and seccomp restrictions applied
+// to a sandbox. Name, if set, references a built-in profile (see
+// internal/runtime/secprofile); the explicit fields below are then ignored.
+type SecurityProfile struct {
+	Name    string
+	CapDrop []string
+	CapAdd  []string
+	Seccomp *SeccompProfile
+}
+
+// SandboxStats is a point-in-time snapshot of a sandbox's resource usage.
+type SandboxStats struct {
+	CPUUsageUsec       uint64
+	MemoryCurrentBytes uint64
+	MemoryPeakBytes    uint64
+	PIDs               int
+	Timestamp          time.Time
+}